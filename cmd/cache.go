@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the cache of video statistics",
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show what is in the cache of video statistics",
+	Args:  cobra.NoArgs,
+	Run:   cacheStats,
+}
+
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove expired videos, or all of them with --all, from the cache of video statistics",
+	Args:  cobra.NoArgs,
+	Run:   cachePurge,
+}
+
+var purgeAll bool
+
+// openCache opens the cache of video statistics in cacheDir, or in the default directory if cacheDir is empty.
+func openCache() (*fileopers.VideoStatsCache, error) {
+	fileopers.SetVerbose(verbose)
+	dir := cacheDir
+	if dir == "" {
+		d, err := fileopers.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = d
+	}
+
+	return fileopers.OpenVideoStatsCache(dir, cacheTTL)
+}
+
+// cacheStats is the actual endpoint where cacheStatsCmd is executed.
+func cacheStats(cmd *cobra.Command, args []string) {
+	cache, err := openCache()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer cache.Close()
+	stats, err := cache.Stats()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	fmt.Println("File:", stats.Filename)
+	fmt.Println("Size:", stats.Size, "bytes")
+	fmt.Println("Videos:", stats.Videos)
+	fmt.Println("Expired:", stats.Expired, "(TTL "+cacheTTL.String()+")")
+	if stats.Videos != 0 {
+		fmt.Println("Oldest:", stats.Oldest.Format("2006/01/02 15:04"))
+		fmt.Println("Newest:", stats.Newest.Format("2006/01/02 15:04"))
+	}
+}
+
+// cachePurge is the actual endpoint where cachePurgeCmd is executed.
+func cachePurge(cmd *cobra.Command, args []string) {
+	cache, err := openCache()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer cache.Close()
+	removed, err := cache.Purge(purgeAll)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Println(removed, "videos removed")
+}
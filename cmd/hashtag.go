@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/jcbl1/tiktok_ugc_finder/scraper"
+	"github.com/spf13/cobra"
+)
+
+var hashtagCmd = &cobra.Command{
+	Use:   "hashtag <tag...>",
+	Short: "Collect posts from the pages of hashtags into a JSON file the root command can process",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   hashtag,
+}
+
+// hashtag is the actual endpoint where hashtagCmd is executed.
+func hashtag(cmd *cobra.Command, args []string) {
+	configure()
+	scraper.SetPostsPerTag(postsPerTag)
+	if err := scraper.CollectHashtags(args, output); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -3,14 +3,11 @@ package cmd
 import (
 	"errors"
 	"log"
-	"net/url"
 	"path"
 	"strings"
 
 	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
 	"github.com/jcbl1/tiktok_ugc_finder/scraper"
-	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
-	"github.com/jcbl1/tiktok_ugc_finder/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -36,15 +33,12 @@ func mend(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	configure()
 	if verbose {
-		log.Println("verbose mode")
 		log.Println("filename:", filename)
 	}
-	fileopers.SetVerbose(verbose)
-	fileopers.SetWorkingDir(path.Dir(filename))
-	scraper.SetVerbose(verbose)
-	scraper.SetRecentVideosNum(recentVideosNum)
-	switch strings.ToLower(strings.Split(filename, ".")[len(strings.Split(filename, ","))-1]) {
+	fileopers.SetWorkingDir(path.Dir(filename)) // mends the file where it is, in its own format.
+	switch strings.ToLower(strings.TrimPrefix(path.Ext(filename), ".")) {
 	case "xlsx":
 		scraper.SetResultFormat("xlsx")
 	case "json":
@@ -52,16 +46,6 @@ func mend(cmd *cobra.Command, args []string) {
 	default:
 		log.Fatalln(errors.New("file format not supported"))
 	}
-	scraper.SetHeadless(headless)
-	ugcinfo.SetVerbose(verbose)
-	// if err := ugcinfo.SetMinMaxFollowerCount(minFollowerCount, maxFollowerCount); err != nil {
-	// 	log.Fatalln(err)
-	// }
-	as, err := url.Parse(apiServer)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	utils.SetAPIServer(as)
 
 	if err := scraper.ScrapeUnscraped(filename); err != nil {
 		log.Fatalln(err)
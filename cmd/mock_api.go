@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+	"github.com/spf13/cobra"
+)
+
+var mockAPICmd = &cobra.Command{
+	Use:   "mock-api",
+	Short: "Serve made-up video info like the API server, for working offline",
+	Args:  cobra.NoArgs,
+	Run:   mockAPI,
+}
+
+// Variables to store the flags of mockAPICmd.
+var (
+	mockAPIListen    string
+	mockAPIBusyRate  float64
+	mockAPIErrorRate float64
+	mockAPILatency   time.Duration
+)
+
+// mockAPI is the actual endpoint where mockAPICmd is executed.
+func mockAPI(cmd *cobra.Command, args []string) {
+	utils.SetVerbose(verbose)
+	if mockAPIBusyRate < 0 || mockAPIErrorRate < 0 || mockAPIBusyRate+mockAPIErrorRate > 1 {
+		log.Fatalln(errors.New("--busy-rate and --error-rate must be between 0 and 1, and add up to 1 at most"))
+	}
+	handler := &utils.MockAPI{BusyRate: mockAPIBusyRate, ErrorRate: mockAPIErrorRate, Latency: mockAPILatency}
+	log.Println("Mock API server listening on", mockAPIListen)
+	log.Fatalln(http.ListenAndServe(mockAPIListen, handler))
+}
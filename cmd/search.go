@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/jcbl1/tiktok_ugc_finder/scraper"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query...>",
+	Short: "Find UGCs in TikTok search results and scrape them",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   search,
+}
+
+// search is the actual endpoint where searchCmd is executed.
+func search(cmd *cobra.Command, args []string) {
+	configure()
+	scraper.SetMaxResults(maxResults)
+	if err := scraper.ScrapeSearch(args); err != nil {
+		log.Fatalln(err)
+	}
+}
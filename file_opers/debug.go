@@ -0,0 +1,153 @@
+package fileopers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failure describes the artifacts saved about a failed UGC in <workingDir>/debug/<uniqueId>/. File names are relative to that directory and empty if the artifact is not available.
+type Failure struct {
+	UniqueID   string    `json:"unique_id"`
+	Time       time.Time `json:"time"`
+	Status     string    `json:"status"`
+	URL        string    `json:"url"`
+	Errors     []string  `json:"errors"` // the error chain, outermost first
+	Screenshot string    `json:"screenshot"`
+	HTML       string    `json:"html"`
+	ConsoleLog string    `json:"console_log"`
+	ErrorFile  string    `json:"error_file"`
+}
+
+// Artifacts are what is collected from the browser when a UGC fails. Any of them may be empty, e.g. when the failure happened after the tab moved on.
+type Artifacts struct {
+	URL        string
+	Screenshot []byte
+	HTML       string
+	Console    []string
+}
+
+// indexMu serializes updates of the debug index.
+var indexMu sync.Mutex
+
+// debugDir returns the directory of debug artifacts.
+func debugDir() string {
+	return filepath.Join(workingDir, "debug")
+}
+
+// SaveFailure saves artifacts about uniqueID failing with status because of err in <workingDir>/debug/<uniqueId>/ and adds them to the index <workingDir>/debug/index.json, which lists every failure saved.
+func SaveFailure(uniqueID, status string, err error, artifacts Artifacts) (Failure, error) {
+	now := time.Now()
+	failure := Failure{
+		UniqueID: uniqueID,
+		Time:     now,
+		Status:   status,
+		URL:      artifacts.URL,
+		Errors:   ErrorChain(err),
+	}
+	dir := filepath.Join(debugDir(), safeName(uniqueID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return failure, err
+	}
+	prefix := now.Local().Format("20060102150405.000") + "-"
+
+	var errs []error
+	if len(artifacts.Screenshot) != 0 {
+		name, err := SaveScreenshotIn(dir, artifacts.Screenshot)
+		failure.Screenshot = name
+		errs = append(errs, err)
+	}
+	write := func(name, content string) string {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			errs = append(errs, err)
+			return ""
+		}
+		return name
+	}
+	if artifacts.HTML != "" {
+		failure.HTML = write(prefix+"page.html", artifacts.HTML)
+	}
+	if len(artifacts.Console) != 0 {
+		failure.ConsoleLog = write(prefix+"console.log", strings.Join(artifacts.Console, "\n")+"\n")
+	}
+	failure.ErrorFile = write(prefix+"error.txt", "URL: "+failure.URL+"\n\n"+strings.Join(failure.Errors, "\n")+"\n")
+
+	errs = append(errs, addToIndex(failure))
+
+	return failure, errors.Join(errs...)
+}
+
+// ReadFailures reads the failures listed in the debug index of the working directory.
+func ReadFailures() ([]Failure, error) {
+	data, err := os.ReadFile(filepath.Join(debugDir(), "index.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var failures []Failure
+	if err := json.Unmarshal(data, &failures); err != nil {
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+// addToIndex appends failure to the debug index.
+func addToIndex(failure Failure) error {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	failures, err := ReadFailures()
+	if err != nil {
+		return err
+	}
+	failures = append(failures, failure)
+	data, err := json.MarshalIndent(failures, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(debugDir(), "index.json"), data, 0644)
+}
+
+// ErrorChain returns the messages of err and the errors it wraps, outermost first. Errors joined by errors.Join or wrapped by fmt.Errorf with several %w are walked depth first.
+func ErrorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(err error) {
+		for err != nil {
+			chain = append(chain, err.Error())
+			switch e := err.(type) {
+			case interface{ Unwrap() []error }:
+				for _, inner := range e.Unwrap() {
+					walk(inner)
+				}
+				return
+			default:
+				err = errors.Unwrap(err)
+			}
+		}
+	}
+	walk(err)
+
+	return chain
+}
+
+// safeName returns name with characters that are not safe in file names replaced.
+func safeName(name string) string {
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == 0 {
+			return '_'
+		}
+		return r
+	}, name)
+}
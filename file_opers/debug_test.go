@@ -0,0 +1,59 @@
+package fileopers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorChain(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	err := fmt.Errorf("scraping: %w", errors.Join(fmt.Errorf("api: %w", errA), errB))
+	want := []string{"scraping: api: a\nb", "api: a\nb", "api: a", "a", "b"}
+	if got := ErrorChain(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("ErrorChain() = %q, want %q", got, want)
+	}
+	if got := ErrorChain(nil); got != nil {
+		t.Errorf("ErrorChain(nil) = %q", got)
+	}
+}
+
+func TestSaveFailure(t *testing.T) {
+	SetWorkingDir(t.TempDir())
+	for _, id := range []string{"alice", "../bob"} {
+		_, err := SaveFailure(id, "timeout", fmt.Errorf("waiting: %w", errors.New("deadline exceeded")), Artifacts{
+			URL:        "https://www.tiktok.com/@" + id,
+			Screenshot: []byte("png"),
+			HTML:       "<html></html>",
+			Console:    []string{"12:00:00.000 [error] oops"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failures, err := ReadFailures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 2 {
+		t.Fatalf("%d failures in the index, want 2", len(failures))
+	}
+	alice := failures[0]
+	dir := filepath.Join(workingDir, "debug", "alice")
+	for _, name := range []string{alice.Screenshot, alice.HTML, alice.ConsoleLog, alice.ErrorFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); name == "" || err != nil {
+			t.Errorf("artifact %q of %+v is missing", name, alice)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, alice.ErrorFile)); !strings.Contains(string(data), "https://www.tiktok.com/@alice") || !strings.Contains(string(data), "deadline exceeded") {
+		t.Errorf("error file = %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(workingDir, "debug", "_bob")); err != nil { // stays in the debug directory.
+		t.Error(err)
+	}
+}
@@ -0,0 +1,127 @@
+package fileopers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// Journal records processed UGCs line by line in a JSONL file in the working directory, so that an interrupted run can be resumed.
+type Journal struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// journalFilename returns the path of the journal file for the input file named name.
+func journalFilename(name string) string {
+	base := filepath.Base(name)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return workingDir + "/journal-" + base + ".jsonl"
+}
+
+// OpenJournal opens the journal for the input file named name. If resume is false, the journal is truncated so that a new run starts from scratch.
+func OpenJournal(name string, resume bool) (*Journal, error) {
+	flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flag |= os.O_TRUNC
+	}
+	filename := journalFilename(name)
+	f, err := os.OpenFile(filename, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := endLine(filename, f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if verbose {
+		log.Println("Journal:", filename)
+	}
+
+	return &Journal{f: f}, nil
+}
+
+// endLine ends the last line of the file named filename, which is open for appending as f, if it is truncated (e.g. by a crash), so that the next line appended is not joined to it.
+func endLine(filename string, f *os.File) error {
+	r, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	info, err := r.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := r.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+	if last[0] != '\n' {
+		_, err = f.Write([]byte{'\n'})
+	}
+
+	return err
+}
+
+// Record appends ugc to the journal. It is safe to call Record on a nil Journal, in which case nothing is recorded.
+func (j *Journal) Record(ugc ugcinfo.UGCInfo) error {
+	if j == nil {
+		return nil
+	}
+	data, err := json.Marshal(ugc)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.f.Close()
+}
+
+// ReadJournal reads the journal for the input file named name and returns the last record of each unique ID. A missing journal is not an error. Lines that cannot be parsed (e.g. the last line of a crashed run) are skipped.
+func ReadJournal(name string) (map[string]ugcinfo.UGCInfo, error) {
+	records := make(map[string]ugcinfo.UGCInfo)
+	f, err := os.Open(journalFilename(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // long signatures and emails make long lines.
+	for scanner.Scan() {
+		var ugc ugcinfo.UGCInfo
+		if err := json.Unmarshal(scanner.Bytes(), &ugc); err != nil || ugc.UniqueID == "" {
+			if verbose {
+				log.Println("Skipping broken journal line:", scanner.Text())
+			}
+			continue
+		}
+		records[ugc.UniqueID] = ugc
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,69 @@
+package fileopers
+
+import (
+	"os"
+	"testing"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+func TestJournal(t *testing.T) {
+	SetWorkingDir(t.TempDir())
+	name := "/some/where/posts.json"
+
+	j, err := OpenJournal(name, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ugc := range []ugcinfo.UGCInfo{
+		{UniqueID: "a", Status: ugcinfo.StatusTimeout},
+		{UniqueID: "b", Status: ugcinfo.StatusOK, AP: 42},
+		{UniqueID: "a", Status: ugcinfo.StatusOK, AP: 7},
+	} {
+		if err := j.Record(ugc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(journalFilename(name), os.O_WRONLY|os.O_APPEND, 0644) // simulates a line cut off by a crash.
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"unique_id":"c","st`)
+	f.Close()
+
+	records, err := ReadJournal(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("len(records) = %d, want 2", len(records))
+	}
+	if records["a"].AP != 7 || records["a"].Status != ugcinfo.StatusOK {
+		t.Errorf("records[a] = %+v, want the last record", records["a"])
+	}
+
+	j, err = OpenJournal(name, true) // resuming after the crash.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record(ugcinfo.UGCInfo{UniqueID: "c", Status: ugcinfo.StatusOK, AP: 3}); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	if records, err := ReadJournal(name); err != nil || len(records) != 3 || records["c"].AP != 3 {
+		t.Errorf("records after resuming: %v, %v, want c recorded after the cut off line", records, err)
+	}
+
+	j, err = OpenJournal(name, false) // starting over truncates the journal.
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	if records, err := ReadJournal(name); err != nil || len(records) != 0 {
+		t.Errorf("records after truncating: %v, %v", records, err)
+	}
+}
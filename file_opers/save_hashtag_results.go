@@ -0,0 +1,28 @@
+package fileopers
+
+import (
+	"encoding/json"
+	"os"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// SaveHashtagResults saves posts in a JSON file named filename, which can be read by ugcinfo.FromJSON. A new file is created in the working directory if filename is empty. The name of the file is returned.
+func SaveHashtagResults(posts ugcinfo.HashtagResults, filename string) (string, error) {
+	if filename == "" {
+		filename = genFilenameWithPrefix("hashtag", "json")
+	}
+	if posts == nil { // writes [] rather than null.
+		posts = ugcinfo.HashtagResults{}
+	}
+	data, err := json.MarshalIndent(posts, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", err
+	}
+	logResultsSaved(filename)
+
+	return filename, nil
+}
@@ -0,0 +1,35 @@
+package fileopers
+
+import (
+	"path/filepath"
+	"testing"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+func TestSaveHashtagResults(t *testing.T) {
+	SetWorkingDir(t.TempDir())
+	posts := ugcinfo.HashtagResults{
+		{Author: ugcinfo.HashtagResultAuthor{UniqueID: "alice", Nickname: "Alice"}, AuthorStats: ugcinfo.HashtagResultAuthorStats{FollowerCount: 12300}, CreatedTime: 1700000000, Desc: "#skincare"},
+		{Author: ugcinfo.HashtagResultAuthor{UniqueID: "alice", Nickname: "Alice"}, AuthorStats: ugcinfo.HashtagResultAuthorStats{FollowerCount: 12300}, CreatedTime: 1700000100, Desc: "#ugc"},
+		{Author: ugcinfo.HashtagResultAuthor{UniqueID: "bob", Nickname: "Bob"}, AuthorStats: ugcinfo.HashtagResultAuthorStats{FollowerCount: 900}, CreatedTime: 1700000200},
+	}
+	filename, err := SaveHashtagResults(posts, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(filename) != workingDir {
+		t.Errorf("saved at %s, want in the working directory", filename)
+	}
+
+	if err := ugcinfo.SetMinMaxFollowerCount("0", "INF"); err != nil {
+		t.Fatal(err)
+	}
+	ugcs, err := ugcinfo.FromJSON(filename) // the root command reads the file unchanged.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ugcs) != 2 || ugcs[0].UniqueID != "alice" || ugcs[0].FollowerCount != 12300 || ugcs[1].Name != "Bob" {
+		t.Errorf("read back %+v", ugcs)
+	}
+}
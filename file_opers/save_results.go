@@ -15,8 +15,13 @@ import (
 )
 
 func genFilename(ext string) string {
+	return genFilenameWithPrefix("result", ext)
+}
+
+// genFilenameWithPrefix generates a unique filename in the working directory starting with prefix.
+func genFilenameWithPrefix(prefix, ext string) string {
 	uuidHead := strings.Split(uuid.NewString(), "-")[0]
-	return workingDir + "/result-" + time.Now().Format("20060102-") + uuidHead + "." + ext
+	return workingDir + "/" + prefix + "-" + time.Now().Format("20060102-") + uuidHead + "." + ext
 }
 
 // SaveResultsAsJSON saves ugcs in a JSON file.
@@ -85,6 +90,13 @@ func SaveResultsAsXLSX(ugcs []ugcinfo.UGCInfo) error {
 		if err := excel.SetCellStr(sheet, fmt.Sprintf("I%d", i+2), ugc.LatestVideoTime.Format("2006/01/02")); err != nil {
 			return err
 		}
+		if err := setStatusCells(excel, sheet, i+2, ugc); err != nil {
+			return err
+		}
+	}
+
+	if err := setVideosSheet(excel, ugcs); err != nil { // lists the videos of each ugc in another sheet.
+		return err
 	}
 
 	filename := genFilename("xlsx")
@@ -135,6 +147,78 @@ func setSheetHeaders(excel *excelize.File, sheet string) error {
 	if err := excel.SetCellStr(sheet, "I1", "Latest Video Time"); err != nil {
 		return err
 	}
+	if err := excel.SetCellStr(sheet, "J1", "Status"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "K1", "Error"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "L1", "Following Count"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "M1", "Heart Count"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "N1", "Video Count"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "O1", "Verified"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "P1", "Private"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "Q1", "Region"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "R1", "Language"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "S1", "Bio Link"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, "T1", "Avatar"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setStatusCells writes the status of ugc and what was read on its profile page besides the main columns, i.e. columns J to T, to row of sheet.
+func setStatusCells(excel *excelize.File, sheet string, row int, ugc ugcinfo.UGCInfo) error {
+	if err := excel.SetCellStr(sheet, fmt.Sprintf("J%d", row), string(ugc.Status)); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, fmt.Sprintf("K%d", row), ugc.ErrorMessage); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(sheet, fmt.Sprintf("L%d", row), ugc.FollowingCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(sheet, fmt.Sprintf("M%d", row), ugc.HeartCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(sheet, fmt.Sprintf("N%d", row), ugc.VideoCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellBool(sheet, fmt.Sprintf("O%d", row), ugc.Verified); err != nil {
+		return err
+	}
+	if err := excel.SetCellBool(sheet, fmt.Sprintf("P%d", row), ugc.Private); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, fmt.Sprintf("Q%d", row), ugc.Region); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, fmt.Sprintf("R%d", row), ugc.Language); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, fmt.Sprintf("S%d", row), ugc.BioLink); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(sheet, fmt.Sprintf("T%d", row), ugc.Avatar); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -150,21 +234,29 @@ func Merge(ugcs *[]ugcinfo.UGCInfo, filename string) error {
 	if err != nil {
 		return err
 	}
+	var mended []ugcinfo.UGCInfo // ugcs whose videos are to be added to the Videos sheet
 	for i, row := range sheet {
-		if row[5] == "0" {
+		if i != 0 && ugcinfo.NeedsMending(row) { // leaves rows already done alone.
 			for _, ugc := range *ugcs {
 				if ugc.UniqueID == row[2] {
 					if err := excel.SetCellInt(sheetName, fmt.Sprintf("F%d", i+1), ugc.AP); err != nil {
 						return err
 					}
-					if err := excel.SetCellFloat(sheetName, fmt.Sprintf("G%d", i+1), float64(ugc.AP), 4, 32); err != nil {
+					if err := excel.SetCellFloat(sheetName, fmt.Sprintf("G%d", i+1), float64(ugc.AI), 4, 32); err != nil {
 						return err
 					}
+					if err := setStatusCells(excel, sheetName, i+1, ugc); err != nil {
+						return err
+					}
+					mended = append(mended, ugc)
 					break
 				}
 			}
 		}
 	}
+	if err := setVideosSheet(excel, mended); err != nil {
+		return err
+	}
 
 	if err := excel.Save(); err != nil {
 		return err
@@ -174,6 +266,128 @@ func Merge(ugcs *[]ugcinfo.UGCInfo, filename string) error {
 	return nil
 }
 
+// videosSheet is the name of the sheet listing the videos sampled for each UGC.
+const videosSheet = "Videos"
+
+// videosSheetHeaders are the headers of the columns of the Videos sheet, starting from A.
+var videosSheetHeaders = []string{"Unique ID", "Video ID", "URL", "Create Time", "Play Count", "Digg Count", "Comment Count", "Share Count", "Collect Count", "Duration (s)", "Description", "Hashtags", "Music", "Music Author"}
+
+// setVideosSheet appends the videos of ugcs to the Videos sheet of excel, one row for each video. The sheet is created with headers if it does not exist yet.
+func setVideosSheet(excel *excelize.File, ugcs []ugcinfo.UGCInfo) error {
+	index, err := excel.GetSheetIndex(videosSheet)
+	if err != nil {
+		return err
+	}
+	if index == -1 {
+		if _, err := excel.NewSheet(videosSheet); err != nil {
+			return err
+		}
+		if err := setVideosSheetHeaders(excel); err != nil {
+			return err
+		}
+	}
+	rows, err := excel.GetRows(videosSheet)
+	if err != nil {
+		return err
+	}
+
+	row := len(rows) + 1 // the first empty row
+	for _, ugc := range ugcs {
+		for _, video := range ugc.VideosStats {
+			if err := setVideoRow(excel, row, ugc.UniqueID, video); err != nil {
+				return err
+			}
+			row++
+		}
+	}
+
+	return nil
+}
+
+// setVideoRow writes video of the UGC with uniqueID to row of the Videos sheet of excel.
+func setVideoRow(excel *excelize.File, row int, uniqueID string, video ugcinfo.VideoStats) error {
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("A%d", row), uniqueID); err != nil {
+		return err
+	}
+	if err := excel.SetCellHyperLink(videosSheet, fmt.Sprintf("A%d", row), "https://www.tiktok.com/@"+uniqueID, "External"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("B%d", row), video.ID); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("C%d", row), video.URL); err != nil {
+		return err
+	}
+	if err := excel.SetCellHyperLink(videosSheet, fmt.Sprintf("C%d", row), video.URL, "External"); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("D%d", row), video.CreateTime.Format("2006/01/02 15:04")); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(videosSheet, fmt.Sprintf("E%d", row), video.PlayCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(videosSheet, fmt.Sprintf("F%d", row), video.DiggCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(videosSheet, fmt.Sprintf("G%d", row), video.CommentCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(videosSheet, fmt.Sprintf("H%d", row), video.ShareCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(videosSheet, fmt.Sprintf("I%d", row), video.CollectCount); err != nil {
+		return err
+	}
+	if err := excel.SetCellInt(videosSheet, fmt.Sprintf("J%d", row), video.Duration); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("K%d", row), video.Description); err != nil {
+		return err
+	}
+	var hashtags []string
+	for _, hashtag := range video.Hashtags {
+		hashtags = append(hashtags, "#"+hashtag)
+	}
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("L%d", row), strings.Join(hashtags, " ")); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("M%d", row), video.MusicTitle); err != nil {
+		return err
+	}
+	if err := excel.SetCellStr(videosSheet, fmt.Sprintf("N%d", row), video.MusicAuthor); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setVideosSheetHeaders writes the headers of the Videos sheet of excel in bold.
+func setVideosSheetHeaders(excel *excelize.File) error {
+	style, err := excel.NewStyle(
+		&excelize.Style{
+			Font: &excelize.Font{Bold: true},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if err := excel.SetRowStyle(videosSheet, 1, 1, style); err != nil {
+		return err
+	}
+	for i, header := range videosSheetHeaders {
+		cell, err := excelize.CoordinatesToCellName(i+1, 1)
+		if err != nil {
+			return err
+		}
+		if err := excel.SetCellStr(videosSheet, cell, header); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func logResultsSaved(filename string) {
 	log.Println("Results saved at", filename)
 }
@@ -0,0 +1,84 @@
+package fileopers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestVideosSheet(t *testing.T) {
+	SetWorkingDir(t.TempDir())
+	video := func(id string, plays int) ugcinfo.VideoStats {
+		return ugcinfo.VideoStats{URL: "https://www.tiktok.com/@alice/video/" + id, ID: id, CreateTime: time.Unix(1700000000, 0), PlayCount: plays, DiggCount: plays / 10, CollectCount: plays / 5, Description: "#ugc", Hashtags: []string{"ugc"}, Duration: 15}
+	}
+	ugcs := []ugcinfo.UGCInfo{
+		{UniqueID: "alice", AP: 150, Status: ugcinfo.StatusOK, VideosStats: []ugcinfo.VideoStats{video("2", 200), video("1", 100)}},
+		{UniqueID: "bob", Status: ugcinfo.StatusAPIError, ErrorMessage: "api error: busy"}, // not scraped yet
+		{UniqueID: "carol", Status: ugcinfo.StatusPrivate},                                 // done with AP 0
+	}
+	if err := SaveResultsAsXLSX(ugcs); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(workingDir, "result-*.xlsx"))
+	if len(files) != 1 {
+		t.Fatalf("saved %v, want a single file", files)
+	}
+
+	rows := videosRows(t, files[0])
+	if len(rows) != 3 || rows[1][0] != "alice" || rows[1][1] != "2" || rows[1][4] != "200" || rows[2][1] != "1" || rows[2][8] != "20" || rows[2][9] != "15" || rows[2][10] != "#ugc" || rows[2][11] != "#ugc" {
+		t.Errorf("Videos sheet = %v", rows)
+	}
+	excel, err := excelize.OpenFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, link, _ := excel.GetCellHyperLink(videosSheet, "C2"); !ok || link != ugcs[0].VideosStats[0].URL {
+		t.Errorf("C2 links to %q, want the video", link)
+	}
+	excel.Close()
+
+	ugcs[1].AP = 300 // mended
+	ugcs[1].AI = 0.25
+	ugcs[1].Status, ugcs[1].ErrorMessage = ugcinfo.StatusOK, ""
+	ugcs[1].HeartCount = 42
+	ugcs[1].VideosStats = []ugcinfo.VideoStats{video("3", 300)}
+	ugcs[2].AP, ugcs[2].Status = 500, ugcinfo.StatusOK
+	if err := Merge(&ugcs, files[0]); err != nil {
+		t.Fatal(err)
+	}
+	rows = sheetRows(t, files[0], "Sheet1")
+	if rows[2][5] != "300" || rows[2][6] != "0.25" || rows[2][9] != "ok" || len(rows[2]) > 10 && rows[2][10] != "" || rows[2][12] != "42" {
+		t.Errorf("mended row = %q, want AP, AI, status, error and profile updated", rows[2])
+	}
+	if rows[3][5] != "0" || rows[3][9] != "private" {
+		t.Errorf("private row = %q, want it left alone", rows[3])
+	}
+	if rows := videosRows(t, files[0]); len(rows) != 4 || rows[3][0] != "bob" || rows[3][1] != "3" {
+		t.Errorf("Videos sheet after merging = %v", rows)
+	}
+}
+
+// videosRows returns the rows of the Videos sheet of the XLSX file filename.
+func videosRows(t *testing.T, filename string) [][]string {
+	t.Helper()
+	return sheetRows(t, filename, videosSheet)
+}
+
+// sheetRows returns the rows of sheet of the XLSX file filename.
+func sheetRows(t *testing.T, filename, sheet string) [][]string {
+	t.Helper()
+	excel, err := excelize.OpenFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer excel.Close()
+	rows, err := excel.GetRows(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rows
+}
@@ -2,6 +2,7 @@ package fileopers
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -10,18 +11,24 @@ import (
 
 // SaveScreenshot saves a screenshot (ss) in a png file.
 func SaveScreenshot(ss []byte) error {
+	_, err := SaveScreenshotIn(workingDir, ss)
+	return err
+}
+
+// SaveScreenshotIn saves a screenshot (ss) in a png file in dir, and returns the name of the file.
+func SaveScreenshotIn(dir string, ss []byte) (string, error) {
 	tail := uuid.NewString()
 	tail = strings.Split(tail, "-")[0]
 	filename := "screenshot-" + time.Now().Local().Format("20060102150405") + "-" + tail + ".png"
-	f, err := os.OpenFile(workingDir+"/"+filename, os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := os.OpenFile(filepath.Join(dir, filename), os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer f.Close()
 
 	_, err = f.Write(ss)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return filename, nil
 }
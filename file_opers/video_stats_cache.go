@@ -0,0 +1,217 @@
+package fileopers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// videoStatsCacheFile is the name of the file of a VideoStatsCache in its directory.
+const videoStatsCacheFile = "video_stats.jsonl"
+
+// CachedVideoStats is the statistics of a video in a VideoStatsCache along with when they were fetched.
+type CachedVideoStats struct {
+	ugcinfo.VideoStats
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// VideoStatsCache keeps the statistics of videos line by line in a JSONL file, so that they are not asked for again by later runs for a while. Entries are keyed by video IDs, the last line of a video wins.
+type VideoStatsCache struct {
+	mu       sync.Mutex
+	filename string
+	ttl      time.Duration
+	entries  map[string]CachedVideoStats
+	f        *os.File
+	now      func() time.Time // for testing
+}
+
+// DefaultCacheDir returns the directory where caches are kept unless told otherwise.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tiktok_ugc_finder"), nil
+}
+
+// OpenVideoStatsCache opens the cache in dir, creating it if needed. Entries fetched longer than ttl ago are expired, they are kept on disk until purged but never returned by Get.
+func OpenVideoStatsCache(dir string, ttl time.Duration) (*VideoStatsCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &VideoStatsCache{filename: filepath.Join(dir, videoStatsCacheFile), ttl: ttl, now: time.Now}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(c.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	c.f = f
+	if verbose {
+		log.Println("Video stats cache:", c.filename, "with", len(c.entries), "videos")
+	}
+
+	return c, nil
+}
+
+// load reads the entries on disk. Lines that cannot be parsed (e.g. the last line of a crashed run) are skipped.
+func (c *VideoStatsCache) load() error {
+	c.entries = make(map[string]CachedVideoStats)
+	f, err := os.Open(c.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // long descriptions make long lines.
+	for scanner.Scan() {
+		var entry CachedVideoStats
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.ID == "" {
+			continue
+		}
+		c.entries[entry.ID] = entry
+	}
+
+	return scanner.Err()
+}
+
+// Get returns the statistics of the video with id, unless they are not cached or expired. It is safe to call Get on a nil VideoStatsCache, which caches nothing.
+func (c *VideoStatsCache) Get(id string) (ugcinfo.VideoStats, bool) {
+	if c == nil {
+		return ugcinfo.VideoStats{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || c.expired(entry) {
+		return ugcinfo.VideoStats{}, false
+	}
+
+	return entry.VideoStats, true
+}
+
+// Put caches vs, which have just been fetched. It is safe to call Put on a nil VideoStatsCache.
+func (c *VideoStatsCache) Put(vs ugcinfo.VideoStats) error {
+	if c == nil || vs.ID == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := CachedVideoStats{VideoStats: vs, FetchedAt: c.now()}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := c.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	c.entries[vs.ID] = entry
+
+	return nil
+}
+
+// expired tells whether entry is too old to be returned.
+func (c *VideoStatsCache) expired(entry CachedVideoStats) bool {
+	return c.now().Sub(entry.FetchedAt) > c.ttl
+}
+
+// CacheStats describes a VideoStatsCache.
+type CacheStats struct {
+	Filename string
+	Size     int64 // of the file, in bytes
+	Videos   int
+	Expired  int
+	Oldest   time.Time // fetch time of the oldest video, zero if there are none
+	Newest   time.Time
+}
+
+// Stats returns what is in c.
+func (c *VideoStatsCache) Stats() (CacheStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := CacheStats{Filename: c.filename, Videos: len(c.entries)}
+	info, err := c.f.Stat()
+	if err != nil {
+		return stats, err
+	}
+	stats.Size = info.Size()
+	for _, entry := range c.entries {
+		if c.expired(entry) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || entry.FetchedAt.Before(stats.Oldest) {
+			stats.Oldest = entry.FetchedAt
+		}
+		if entry.FetchedAt.After(stats.Newest) {
+			stats.Newest = entry.FetchedAt
+		}
+	}
+
+	return stats, nil
+}
+
+// Purge removes the expired videos from c, or all of them if all is true, and rewrites the file with the videos left, dropping stale lines too. It returns the number of videos removed.
+func (c *VideoStatsCache) Purge(all bool) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for id, entry := range c.entries {
+		if all || c.expired(entry) {
+			delete(c.entries, id)
+			removed++
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.filename), videoStatsCacheFile+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed.
+	w := bufio.NewWriter(tmp)
+	for _, entry := range c.entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return 0, err
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), c.filename); err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(c.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644) // the old file is gone.
+	if err != nil {
+		return 0, err
+	}
+	c.f.Close()
+	c.f = f
+
+	return removed, nil
+}
+
+// Close closes the file of c.
+func (c *VideoStatsCache) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.f.Close()
+}
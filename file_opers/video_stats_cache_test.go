@@ -0,0 +1,91 @@
+package fileopers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+func TestVideoStatsCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := OpenVideoStatsCache(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	video := func(id string, plays int) ugcinfo.VideoStats {
+		return ugcinfo.VideoStats{ID: id, URL: "https://www.tiktok.com/@alice/video/" + id, CreateTime: time.Unix(1700000000, 0), PlayCount: plays}
+	}
+	if err := c.Put(video("1", 100)); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Hour)
+	if err := c.Put(video("2", 200)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(video("2", 250)); err != nil { // fetched again
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("1"); ok {
+		t.Error("got the expired video 1")
+	}
+	if vs, ok := c.Get("2"); !ok || vs.PlayCount != 250 || vs.CreateTime.Unix() != 1700000000 {
+		t.Errorf("Get(2) = %+v, %v", vs, ok)
+	}
+	c.Close()
+
+	c, err = OpenVideoStatsCache(dir, 24*time.Hour) // a later run with a longer TTL
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if vs, ok := c.Get("1"); !ok || vs.PlayCount != 100 {
+		t.Errorf("Get(1) = %+v, %v after reopening", vs, ok)
+	}
+	c.now = func() time.Time { return now.Add(23 * time.Hour) }
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Videos != 2 || stats.Expired != 1 || stats.Size == 0 || !stats.Newest.After(stats.Oldest) {
+		t.Errorf("Stats() = %+v, want 2 videos with 1 expired", stats)
+	}
+
+	if removed, err := c.Purge(false); err != nil || removed != 1 {
+		t.Errorf("Purge(false) = %d, %v, want video 1 removed", removed, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, videoStatsCacheFile))
+	if lines := len(splitLines(data)); lines != 1 {
+		t.Errorf("%d lines left after purging, want the latest line of video 2 only", lines)
+	}
+	if err := c.Put(video("3", 300)); err != nil { // still writable after purging
+		t.Fatal(err)
+	}
+	if removed, err := c.Purge(true); err != nil || removed != 2 {
+		t.Errorf("Purge(true) = %d, %v, want 2 videos removed", removed, err)
+	}
+	if _, ok := c.Get("3"); ok {
+		t.Error("got video 3 after purging all")
+	}
+
+	var nilCache *VideoStatsCache
+	if _, ok := nilCache.Get("2"); ok || nilCache.Put(video("2", 200)) != nil {
+		t.Error("a nil cache caches")
+	}
+}
+
+// splitLines returns the non-empty lines in data.
+func splitLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
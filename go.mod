@@ -9,7 +9,7 @@ require (
 	github.com/google/uuid v1.5.0
 	github.com/spf13/cobra v1.8.0
 	github.com/xuri/excelize/v2 v2.8.0
-	golang.org/x/sync v0.1.0
+	golang.org/x/net v0.14.0
 )
 
 require (
@@ -27,7 +27,6 @@ require (
 	github.com/xuri/efp v0.0.0-20230802181842-ad255f2331ca // indirect
 	github.com/xuri/nfp v0.0.0-20230819163627-dc951e3ffe1a // indirect
 	golang.org/x/crypto v0.12.0 // indirect
-	golang.org/x/net v0.14.0 // indirect
 	golang.org/x/sys v0.11.0 // indirect
 	golang.org/x/text v0.12.0 // indirect
 )
@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"context"
+	"log"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/chromedp"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// newAllocator returns an allocator context used to start the browser. It respects headless, userDataDir and the proxy set in [utils].
+//
+// If browserWSURL is set, no browser is started and the one listening there is used instead. Tabs are opened and closed in it but it is never closed.
+func newAllocator(ctxParent context.Context) (context.Context, context.CancelFunc) {
+	if browserWSURL != "" {
+		if verbose && (userDataDir != "" || headless || utils.Proxy() != nil) {
+			log.Println("Using the remote browser at", browserWSURL+", --user-data-dir, --headless and the proxy server are up to it")
+		}
+		return chromedp.NewRemoteAllocator(ctxParent, browserWSURL)
+	}
+
+	opts := chromedp.DefaultExecAllocatorOptions[:]
+	if !headless { // customizes options used to allocate a browser.
+		opts = append(opts, chromedp.Flag("headless", false), chromedp.DisableGPU)
+	}
+	if userDataDir != "" { // keeps logins, language and region settings across runs.
+		opts = append(opts, chromedp.UserDataDir(userDataDir))
+	}
+	if proxy := utils.Proxy(); proxy != nil { // credentials are not accepted here, see prepareTab.
+		opts = append(opts, chromedp.ProxyServer(proxy.Scheme+"://"+proxy.Host))
+		if bypass := utils.ProxyBypassList(); bypass != "" {
+			opts = append(opts, chromedp.Flag("proxy-bypass-list", bypass))
+		}
+		if proxy.Scheme == "socks5" && proxy.User != nil {
+			log.Println("Chrome does not support authentication of SOCKS proxies, the browser may fail to connect")
+		}
+	}
+
+	return chromedp.NewExecAllocator(ctxParent, opts...)
+}
+
+// prepareBrowser does what has to be done in a newly started browser before the first navigation.
+func prepareBrowser(ctx context.Context) error {
+	if cookiesFile == "" {
+		return nil
+	}
+	cookies, err := readCookies(cookiesFile)
+	if err != nil {
+		return err
+	}
+
+	return chromedp.Run(ctx, setCookies(cookies))
+}
+
+// prepareTab does what has to be done in a newly opened tab before the first navigation.
+//
+// If the proxy requires authentication, the tab answers the challenges of the proxy with the credentials in the proxy URL through the Fetch domain.
+func prepareTab(ctx context.Context) error {
+	proxy := utils.Proxy()
+	if proxy == nil || proxy.User == nil {
+		return nil
+	}
+	username := proxy.User.Username()
+	password, _ := proxy.User.Password()
+
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *fetch.EventRequestPaused: // every request is paused once the Fetch domain is enabled.
+			go func() {
+				if err := fetch.ContinueRequest(ev.RequestID).Do(tabExecutor(ctx)); err != nil && verbose {
+					log.Println("Failed to continue request:", err)
+				}
+			}()
+		case *fetch.EventAuthRequired:
+			go func() {
+				resp := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseDefault}
+				if ev.AuthChallenge.Source == fetch.AuthChallengeSourceProxy {
+					resp = &fetch.AuthChallengeResponse{
+						Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+						Username: username,
+						Password: password,
+					}
+				}
+				if err := fetch.ContinueWithAuth(ev.RequestID, resp).Do(tabExecutor(ctx)); err != nil && verbose {
+					log.Println("Failed to answer auth challenge:", err)
+				}
+			}()
+		}
+	})
+
+	return chromedp.Run(ctx, fetch.Enable().WithHandleAuthRequests(true))
+}
+
+// navigate waits for the navigation rate limit, then navigates to u.
+func navigate(u string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := utils.WaitNavigation(ctx); err != nil {
+			return err
+		}
+		return chromedp.Navigate(u).Do(ctx)
+	})
+}
+
+// tabExecutor returns a context executing commands in the tab of ctx, to be used in event listeners.
+func tabExecutor(ctx context.Context) context.Context {
+	return cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+}
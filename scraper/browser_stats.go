@@ -0,0 +1,47 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// itemListSettle limits how long browserProvider waits for the item lists still being read.
+const itemListSettle = 5 * time.Second
+
+// browserProvider provides the statistics of the videos seen in the item lists fetched by the profile pages in the tabs, and asks fallback for the other videos.
+type browserProvider struct {
+	items    *itemCollector // shared by all the tabs, holding the items of the profiles not done with yet
+	fallback VideoStatsProvider
+}
+
+// newBrowserProvider returns a browserProvider falling back on fallback.
+func newBrowserProvider(fallback VideoStatsProvider) *browserProvider {
+	return &browserProvider{items: &itemCollector{}, fallback: fallback}
+}
+
+func (p *browserProvider) listen(ctx context.Context) {
+	p.items.listen(ctx, postItemListPath)
+}
+
+func (p *browserProvider) forget(uniqueID string) {
+	p.items.remove(func(item tiktokItem) bool {
+		return strings.EqualFold(item.Author.UniqueID, uniqueID)
+	})
+}
+
+func (p *browserProvider) VideoStats(ctx context.Context, link string) (ugcinfo.VideoStats, error) {
+	p.items.settle(ctx, itemListSettle)
+	if item, ok := p.items.item(utils.VideoID(link)); ok {
+		return item.videoStats(link), nil
+	}
+	if verbose {
+		log.Println("Video not found in item lists:", link)
+	}
+
+	return p.fallback.VideoStats(ctx, link)
+}
@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+func TestBrowserProvider(t *testing.T) {
+	data, err := os.ReadFile("testdata/item_list/post.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list itemList
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	p := newBrowserProvider(fakeProvider{})
+	p.items.add(list.ItemList)
+
+	link := "https://www.tiktok.com/@fer.faceyoga/video/7309876543210987654"
+	v, err := p.VideoStats(context.Background(), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != "7309876543210987654" || v.URL != link || v.CreateTime.Unix() != 1702100000 || v.PlayCount != 10400 || v.DiggCount != 510 || v.CommentCount != 12 || v.ShareCount != 3 || v.Description != "jawline routine" {
+		t.Errorf("VideoStats(%q) = %+v", link, v)
+	}
+
+	link = "https://www.tiktok.com/@fer.faceyoga/video/7310293679493614853"
+	v, _ = p.VideoStats(context.Background(), link)
+	if v.CollectCount != 120 || v.Duration != 42 || !reflect.DeepEqual(v.Hashtags, []string{"faceyoga", "ugc"}) || v.MusicTitle != "original sound - fer.faceyoga" || v.MusicAuthor != "Fer" {
+		t.Errorf("VideoStats(%q) = %+v", link, v)
+	}
+
+	p.items.add([]tiktokItem{{ID: "1", Author: ugcinfo.HashtagResultAuthor{UniqueID: "alice"}}})
+	p.forget("Fer.FaceYoga")
+	if items := p.items.collected(); len(items) != 1 || items[0].ID != "1" {
+		t.Errorf("items after forgetting the profile = %+v, want only the one of another profile", items)
+	}
+	if _, ok := p.items.item("1"); !ok {
+		t.Error("item of another profile not found after forgetting the profile")
+	}
+
+	link = "https://www.tiktok.com/@fer.faceyoga/video/3" // not in the item lists
+	v, err = p.VideoStats(context.Background(), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := (fakeProvider{}).VideoStats(context.Background(), link); !reflect.DeepEqual(v, want) {
+		t.Errorf("VideoStats(%q) = %+v, want %+v from the fallback", link, v, want)
+	}
+}
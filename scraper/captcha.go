@@ -0,0 +1,130 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// maxChallengeAttempts is how many times a profile is tried again after a human solved a challenge on it.
+const maxChallengeAttempts = 3
+
+// Backoff of a tab after a challenge in headless mode. It doubles with every challenge in a row, up to maxChallengeBackoff.
+var (
+	challengeBackoff    = time.Minute
+	maxChallengeBackoff = 15 * time.Minute
+)
+
+// errLoginWall means the login modal could not be closed.
+var errLoginWall = errors.New("login required")
+
+// isChallenge reports whether err is caused by a challenge (a captcha or a login wall) which a human could get past.
+func isChallenge(err error) bool {
+	return errors.Is(err, errCaptcha) || errors.Is(err, errLoginWall)
+}
+
+// checkCaptcha returns errCaptcha if a captcha is shown on the page opened in ctx.
+func checkCaptcha(ctx context.Context) error {
+	var captchaNodes []*cdp.Node
+	if err := chromedp.Run(
+		ctx,
+		firstNodes(selectors.Captcha, &captchaNodes),
+	); err != nil {
+		return err
+	}
+	if len(captchaNodes) != 0 {
+		return errCaptcha
+	}
+
+	return nil
+}
+
+// scrapeProfile is scrapeProfileOnce, with a pause for a human to solve challenges in non-headless mode.
+//
+// In headless mode, challenges are returned as errors right away. Every attempt respects the navigation rate limit.
+func scrapeProfile(ctxTab context.Context, ugc *ugcinfo.UGCInfo, first *bool) ([]string, error) {
+	for attempt := 1; ; attempt++ {
+		if err := utils.WaitNavigation(ctxTab); err != nil { // waits outside of profileTimeout.
+			return nil, err
+		}
+		links, err := scrapeProfileOnce(ctxTab, ugc, first)
+		if !isChallenge(err) || headless || attempt > maxChallengeAttempts {
+			return links, err
+		}
+		if err := waitForHuman(ctxTab, ugc.UniqueID, err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// humanMu makes sure only one tab asks for help at a time.
+var humanMu sync.Mutex
+
+// waitForHuman asks in the terminal for a human to get past challenge in the tab of ctx, and waits until Enter is pressed or the captcha is gone.
+func waitForHuman(ctx context.Context, uniqueID string, challenge error) error {
+	humanMu.Lock()
+	defer humanMu.Unlock()
+
+	fmt.Printf("🧩 %s while scraping %s. Please get past it in the browser, then press Enter.\n", challenge, uniqueID)
+	lines := stdinLines()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-lines:
+			return nil
+		case <-time.After(time.Second):
+			if errors.Is(challenge, errCaptcha) {
+				if err := checkCaptcha(ctx); err == nil {
+					log.Println("Captcha solved")
+					return nil
+				} else if !errors.Is(err, errCaptcha) {
+					return err
+				}
+			}
+		}
+	}
+}
+
+var (
+	stdinOnce  sync.Once
+	stdinLineC chan string
+)
+
+// stdinLines returns a channel of lines read from the standard input. A single goroutine reads the standard input for the whole process.
+func stdinLines() <-chan string {
+	stdinOnce.Do(func() {
+		stdinLineC = make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				stdinLineC <- scanner.Text()
+			}
+		}()
+	})
+
+	return stdinLineC
+}
+
+// challengeBackoffFor returns how long a tab should back off after n challenges in a row.
+func challengeBackoffFor(n int) time.Duration {
+	d := challengeBackoff
+	for i := 1; i < n && d < maxChallengeBackoff; i++ {
+		d *= 2
+	}
+	if d > maxChallengeBackoff {
+		d = maxChallengeBackoff
+	}
+
+	return d
+}
@@ -0,0 +1,19 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChallengeBackoffFor(t *testing.T) {
+	for n, want := range map[int]time.Duration{
+		1:  challengeBackoff,
+		2:  2 * challengeBackoff,
+		3:  4 * challengeBackoff,
+		10: maxChallengeBackoff,
+	} {
+		if got := challengeBackoffFor(n); got != want {
+			t.Errorf("challengeBackoffFor(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// jsonCookie is a cookie exported as JSON by browser extensions like Cookie-Editor and EditThisCookie, or by the DevTools protocol.
+type jsonCookie struct {
+	Name           string   `json:"name"`
+	Value          string   `json:"value"`
+	Domain         string   `json:"domain"`
+	Path           string   `json:"path"`
+	Secure         bool     `json:"secure"`
+	HTTPOnly       bool     `json:"httpOnly"`
+	SameSite       string   `json:"sameSite"`
+	Session        bool     `json:"session"`
+	ExpirationDate *float64 `json:"expirationDate"` // extensions
+	Expires        *float64 `json:"expires"`        // DevTools protocol
+}
+
+// readCookies reads cookies from filename, which is either a Netscape cookies.txt or a JSON cookie export.
+func readCookies(filename string) ([]*network.CookieParam, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var cookies []*network.CookieParam
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		cookies, err = parseJSONCookies(trimmed)
+	} else {
+		cookies, err = parseNetscapeCookies(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if verbose {
+		log.Println(len(cookies), "cookies read from", filename)
+	}
+
+	return cookies, nil
+}
+
+// parseNetscapeCookies parses cookies in the Netscape cookies.txt format, as written by curl, yt-dlp and "Get cookies.txt" extensions.
+func parseNetscapeCookies(data []byte) ([]*network.CookieParam, error) {
+	var cookies []*network.CookieParam
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if rest, ok := strings.CutPrefix(line, "#HttpOnly_"); ok {
+			line = rest
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: %d fields, want 7", n, len(fields))
+		}
+		cookie := &network.CookieParam{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HTTPOnly: httpOnly,
+			Name:     fields[5],
+			Value:    fields[6],
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if expires > 0 { // 0 means a session cookie.
+			t := cdp.TimeSinceEpoch(time.Unix(expires, 0))
+			cookie.Expires = &t
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cookies, nil
+}
+
+// parseJSONCookies parses cookies in a JSON array, or in an object holding the array in "cookies".
+func parseJSONCookies(data []byte) ([]*network.CookieParam, error) {
+	var jcs []jsonCookie
+	if data[0] == '{' {
+		var wrapper struct {
+			Cookies []jsonCookie `json:"cookies"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, err
+		}
+		jcs = wrapper.Cookies
+	} else if err := json.Unmarshal(data, &jcs); err != nil {
+		return nil, err
+	}
+
+	var cookies []*network.CookieParam
+	for _, jc := range jcs {
+		cookie := &network.CookieParam{
+			Name:     jc.Name,
+			Value:    jc.Value,
+			Domain:   jc.Domain,
+			Path:     jc.Path,
+			Secure:   jc.Secure,
+			HTTPOnly: jc.HTTPOnly,
+		}
+		switch strings.ToLower(jc.SameSite) {
+		case "strict":
+			cookie.SameSite = network.CookieSameSiteStrict
+		case "lax":
+			cookie.SameSite = network.CookieSameSiteLax
+		case "none", "no_restriction":
+			cookie.SameSite = network.CookieSameSiteNone
+		}
+		expires := jc.ExpirationDate
+		if expires == nil {
+			expires = jc.Expires
+		}
+		if !jc.Session && expires != nil && *expires > 0 {
+			sec := int64(*expires)
+			t := cdp.TimeSinceEpoch(time.Unix(sec, int64((*expires-float64(sec))*1e9)))
+			cookie.Expires = &t
+		}
+		if cookie.Path == "" {
+			cookie.Path = "/"
+		}
+		cookies = append(cookies, cookie)
+	}
+
+	return cookies, nil
+}
+
+// setCookies returns an action setting cookies in the browser.
+func setCookies(cookies []*network.CookieParam) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		return network.SetCookies(cookies).Do(ctx)
+	})
+}
@@ -0,0 +1,44 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestReadCookies(t *testing.T) {
+	cookies, err := readCookies("testdata/cookies/cookies.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 3 {
+		t.Fatalf("len(cookies) = %d, want 3", len(cookies))
+	}
+	if c := cookies[1]; c.Name != "sessionid" || c.Value != "deadbeef" || !c.HTTPOnly || !c.Secure || c.Domain != ".tiktok.com" || c.Expires == nil || !time.Time(*c.Expires).Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("cookies[1] = %+v", c)
+	}
+	if c := cookies[2]; c.Expires != nil || c.Secure || c.HTTPOnly {
+		t.Errorf("cookies[2] = %+v, want an insecure session cookie", c)
+	}
+
+	cookies, err = readCookies("testdata/cookies/cookies.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("len(cookies) = %d, want 2", len(cookies))
+	}
+	if c := cookies[0]; c.Name != "sessionid" || !c.HTTPOnly || c.SameSite != network.CookieSameSiteNone || c.Expires == nil || time.Time(*c.Expires).Unix() != 1767225600 {
+		t.Errorf("cookies[0] = %+v", c)
+	}
+	if c := cookies[1]; c.Expires != nil || c.SameSite != network.CookieSameSiteLax {
+		t.Errorf("cookies[1] = %+v, want a lax session cookie", c)
+	}
+}
+
+func TestParseNetscapeCookiesMalformed(t *testing.T) {
+	if _, err := parseNetscapeCookies([]byte(".tiktok.com\tTRUE\t/\n")); err == nil {
+		t.Error("parsed a line with missing fields")
+	}
+}
@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	cdplog "github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// maxConsoleLines is how many of the latest console lines of a tab are kept for failure artifacts.
+const maxConsoleLines = 500
+
+// artifactsTimeout limits collecting failure artifacts from a tab.
+const artifactsTimeout = 30 * time.Second
+
+// consoleLog keeps the latest lines logged in the console of a tab.
+type consoleLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// consoleLogKey is the context key of the consoleLog of a tab.
+type consoleLogKey struct{}
+
+// listenConsole starts keeping the console of the tab of ctx, and returns a copy of ctx carrying it for saveFailure.
+func listenConsole(ctx context.Context) context.Context {
+	c := &consoleLog{}
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *runtime.EventConsoleAPICalled:
+			var args []string
+			for _, arg := range ev.Args {
+				args = append(args, remoteObjectString(arg))
+			}
+			c.add(ev.Timestamp, string(ev.Type), strings.Join(args, " "))
+		case *runtime.EventExceptionThrown:
+			text := ev.ExceptionDetails.Text
+			if ev.ExceptionDetails.Exception != nil {
+				text += " " + remoteObjectString(ev.ExceptionDetails.Exception)
+			}
+			c.add(ev.Timestamp, "exception", text+" "+ev.ExceptionDetails.URL)
+		case *cdplog.EventEntryAdded: // e.g. failed requests
+			c.add(ev.Entry.Timestamp, string(ev.Entry.Level), ev.Entry.Text+" "+ev.Entry.URL)
+		}
+	})
+
+	return context.WithValue(ctx, consoleLogKey{}, c)
+}
+
+// add adds a line logged at ts with level.
+func (c *consoleLog) add(ts *runtime.Timestamp, level, text string) {
+	t := time.Now()
+	if ts != nil {
+		t = ts.Time()
+	}
+	line := t.Format("15:04:05.000") + " [" + level + "] " + strings.TrimSpace(text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = append(c.lines, line)
+	if len(c.lines) > maxConsoleLines {
+		c.lines = c.lines[len(c.lines)-maxConsoleLines:]
+	}
+}
+
+// copy returns a copy of the lines kept.
+func (c *consoleLog) copy() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+// remoteObjectString returns what the console shows for o.
+func remoteObjectString(o *runtime.RemoteObject) string {
+	if len(o.Value) != 0 {
+		return strings.Trim(string(o.Value), `"`)
+	}
+	if o.Description != "" {
+		return o.Description
+	}
+	return string(o.Type)
+}
+
+// saveFailure saves artifacts about ugc, which failed because of err, with fileopers.SaveFailure. The page, the screenshot and the console log are taken from the tab of ctxTab, which may be nil if the tab has moved on.
+//
+// UGCs whose profiles are not found or private are not failures and are skipped. Errors are only logged, so that saving artifacts never fails a run.
+func saveFailure(ctxTab context.Context, ugc ugcinfo.UGCInfo, err error) {
+	if ugc.Status.Done() {
+		return
+	}
+
+	var artifacts fileopers.Artifacts
+	if ctxTab != nil && ctxTab.Err() == nil && chromedp.FromContext(ctxTab) != nil {
+		ctx, cancel := context.WithTimeout(ctxTab, artifactsTimeout)
+		defer cancel()
+		if err := chromedp.Run(
+			ctx,
+			chromedp.Location(&artifacts.URL),
+			chromedp.OuterHTML(`html`, &artifacts.HTML, chromedp.ByQuery),
+			chromedp.FullScreenshot(&artifacts.Screenshot, 100), // PNG, lower qualities are JPEG.
+		); err != nil {
+			log.Println("Failed to collect artifacts of", ugc.UniqueID+":", err)
+		}
+		if c, ok := ctxTab.Value(consoleLogKey{}).(*consoleLog); ok {
+			artifacts.Console = c.copy()
+		}
+	}
+
+	failure, serr := fileopers.SaveFailure(ugc.UniqueID, string(ugc.Status), err, artifacts)
+	if serr != nil {
+		log.Println("Failed to save artifacts of", ugc.UniqueID+":", serr)
+	} else if verbose {
+		log.Println("Artifacts of", ugc.UniqueID, "saved:", failure.ErrorFile)
+	}
+}
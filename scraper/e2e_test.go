@@ -0,0 +1,220 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// requireChrome skips the test if no Chrome (or Chromium, headless-shell) can be found.
+func requireChrome(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping end-to-end test in short mode")
+	}
+	for _, name := range []string{"headless-shell", "headless_shell", "chromium", "chromium-browser", "google-chrome", "google-chrome-stable"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return
+		}
+	}
+	t.Skip("no Chrome found")
+}
+
+// useFakeSites points the scraper to a fake TikTok serving profiles and a fake API server, and sets the other variables needed for a headless run. Everything is restored when the test finishes.
+func useFakeSites(t *testing.T, profiles ...fakeProfile) {
+	t.Helper()
+	site := fakeTikTok(t, profiles...)
+	api := fakeAPIServer(t)
+
+	oldTikTok, oldHeadless, oldRecentVideosNum, oldTabs, oldChallengeBackoff := TIKTOK, headless, recentVideosNum, tabs, challengeBackoff
+	t.Cleanup(func() {
+		SetTikTokURL(oldTikTok)
+		headless, recentVideosNum, tabs, challengeBackoff = oldHeadless, oldRecentVideosNum, oldTabs, oldChallengeBackoff
+	})
+	SetTikTokURL(site.URL)
+	headless = true
+	recentVideosNum = 15
+	tabs = 2
+	challengeBackoff = time.Second
+
+	u, err := url.Parse(api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.SetAPIServer(u)
+}
+
+func TestScrapeProfileVideosE2E(t *testing.T) {
+	requireChrome(t)
+	useFakeSites(t,
+		fakeProfile{
+			UniqueID:  "alice",
+			Nickname:  "Alice",
+			Bio:       "UGC creator ✨ collabs: alice@example.com",
+			Verified:  true,
+			Followers: 12300,
+			Videos:    []fakeVideo{{ID: 1, Pinned: true}, {ID: 2}, {ID: 3}, {ID: 4}},
+		},
+		fakeProfile{
+			UniqueID: "bob",
+			Nickname: "Bob",
+			Bio:      "No, this account is private. Kidding!", // not to be taken for a private account.
+			Videos:   []fakeVideo{{ID: 5}, {ID: 6}},
+		},
+		fakeProfile{
+			UniqueID: "carol",
+			Nickname: "Carol",
+			Bio:      "carol@example.com",
+			Private:  true,
+		},
+		fakeProfile{
+			UniqueID: "dave",
+			Captcha:  true,
+		},
+		fakeProfile{
+			UniqueID: "erin",
+			Nickname: "Erin",
+		},
+	)
+
+	ugcs := []ugcinfo.UGCInfo{{UniqueID: "alice"}, {UniqueID: "bob"}, {UniqueID: "carol"}, {UniqueID: "ghost"}, {UniqueID: "dave"}, {UniqueID: "erin"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := scrapeProfileVideos(ctx, &ugcs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := ugcs[0] // the pinned video is left out.
+	if alice.Status != ugcinfo.StatusOK || alice.AP != 300 || alice.LatestVideoTime.Unix() != 2000 {
+		t.Errorf("alice = %+v, want status ok, AP 300 and latest video time 2000", alice)
+	}
+	if v := alice.VideosStats; len(v) != 3 || v[0].ID != "2" || !strings.HasSuffix(v[0].URL, "/@alice/video/2") || v[0].Description != "video 2 #ugc" {
+		t.Errorf("alice.VideosStats = %+v, want videos 2 to 4", v)
+	}
+	if alice.FollowerCount != 12300 || !alice.Verified || alice.VideoCount != 4 || alice.BioLink != "linktr.ee/alice" {
+		t.Errorf("alice = %+v, want profile data from the embedded state", alice)
+	}
+	if len(alice.Email) != 1 || alice.Email[0] != "<alice@example.com>" {
+		t.Errorf("alice.Email = %v", alice.Email)
+	}
+	if bob := ugcs[1]; bob.Status != ugcinfo.StatusOK || bob.AP != 550 || bob.AI < 0.099 || bob.AI > 0.101 {
+		t.Errorf("bob = %+v, want status ok, AP 550 and AI 0.1", bob)
+	}
+	if carol := ugcs[2]; carol.Status != ugcinfo.StatusPrivate || len(carol.Email) != 1 {
+		t.Errorf("carol = %+v, want status private with an email", carol)
+	}
+	if ghost := ugcs[3]; ghost.Status != ugcinfo.StatusNotFound {
+		t.Errorf("ghost = %+v, want status not_found", ghost)
+	}
+	if dave := ugcs[4]; dave.Status != ugcinfo.StatusCaptcha {
+		t.Errorf("dave = %+v, want status captcha", dave)
+	}
+	if erin := ugcs[5]; erin.Status != ugcinfo.StatusOK || erin.AP != 0 || len(erin.VideosStats) != 0 { // without waiting for a grid.
+		t.Errorf("erin = %+v, want status ok without videos", erin)
+	}
+}
+
+func TestSearchE2E(t *testing.T) {
+	requireChrome(t)
+	useFakeSites(t,
+		fakeProfile{UniqueID: "alice", Nickname: "Alice", Bio: "skincare", Followers: 12300, Videos: []fakeVideo{{ID: 1}}},
+		fakeProfile{UniqueID: "bob", Nickname: "Bob", Bio: "skincare", Followers: 50, Videos: []fakeVideo{{ID: 2}}},
+		fakeProfile{UniqueID: "carol", Nickname: "Carol", Followers: 9000, Videos: []fakeVideo{{ID: 3}}},
+	)
+	oldMaxResults := maxResults
+	t.Cleanup(func() { maxResults = oldMaxResults })
+	maxResults = 10
+	if err := ugcinfo.SetMinMaxFollowerCount("1K", "INF"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ugcinfo.SetMinMaxFollowerCount("0", "INF") })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	ugcs, err := Search(ctx, []string{"skincare"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ugcs) != 1 || ugcs[0].UniqueID != "alice" || ugcs[0].Name != "Alice" || ugcs[0].FollowerCount != 12300 {
+		t.Errorf("got %+v, want alice only", ugcs)
+	}
+}
+
+func TestCollectHashtagsE2E(t *testing.T) {
+	requireChrome(t)
+	useFakeSites(t,
+		fakeProfile{UniqueID: "alice", Nickname: "Alice", Bio: "#skincare #ugc", Followers: 12300, Videos: []fakeVideo{{ID: 1}, {ID: 2}, {ID: 3}}},
+		fakeProfile{UniqueID: "bob", Nickname: "Bob", Bio: "#ugc", Followers: 900, Videos: []fakeVideo{{ID: 4}, {ID: 5}}},
+		fakeProfile{UniqueID: "carol", Nickname: "Carol", Bio: "#ugc", Private: true, Videos: []fakeVideo{{ID: 6}}},
+	)
+	oldPostsPerTag := postsPerTag
+	t.Cleanup(func() { postsPerTag = oldPostsPerTag })
+	postsPerTag = 5
+	output := filepath.Join(t.TempDir(), "posts.json")
+
+	if err := CollectHashtags([]string{"#skincare", "ugc"}, output); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var posts ugcinfo.HashtagResults
+	if err := json.Unmarshal(data, &posts); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 5 { // 3 from #skincare, then 5 from #ugc of which 3 are already collected.
+		t.Fatalf("got %d posts, want 5", len(posts))
+	}
+	if p := posts[0]; p.Author.UniqueID != "alice" || p.AuthorStats.FollowerCount != 12300 || p.CreatedTime != 1000 || p.Desc != "#skincare #ugc" {
+		t.Errorf("posts[0] = %+v", p)
+	}
+	if p := posts[4]; p.Author.UniqueID != "bob" || p.CreatedTime != 5000 {
+		t.Errorf("posts[4] = %+v, want the second post of bob", p)
+	}
+}
+
+func TestBrowserStatsE2E(t *testing.T) {
+	requireChrome(t)
+	useFakeSites(t, fakeProfile{UniqueID: "alice", Videos: []fakeVideo{{ID: 1, Pinned: true}, {ID: 2}, {ID: 3}, {ID: 4}}})
+	oldStatsProvider := statsProvider
+	t.Cleanup(func() { statsProvider = oldStatsProvider })
+	statsProvider = newBrowserProvider(apiProvider{})
+	var apiRequests atomic.Int32
+	api := fakeAPIServer(t)
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiRequests.Add(1)
+		api.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(counting.Close)
+	u, err := url.Parse(counting.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.SetAPIServer(u)
+
+	ugcs := []ugcinfo.UGCInfo{{UniqueID: "alice"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := scrapeProfileVideos(ctx, &ugcs, nil); err != nil {
+		t.Fatal(err)
+	}
+	if alice := ugcs[0]; alice.Status != ugcinfo.StatusOK || alice.AP != 300 || len(alice.VideosStats) != 3 || alice.VideosStats[0].CommentCount != 2 {
+		t.Errorf("alice = %+v, want status ok, AP 300 and videos 2 to 4", alice)
+	}
+	if n := apiRequests.Load(); n != 0 {
+		t.Errorf("the API server was asked %d times, want the item list only", n)
+	}
+}
@@ -0,0 +1,293 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeVideo is a video on a fake profile page.
+type fakeVideo struct {
+	ID     int
+	Pinned bool
+	Plays  int
+}
+
+// fakeProfile is a profile served by the fake TikTok.
+type fakeProfile struct {
+	UniqueID  string
+	Nickname  string
+	Bio       string
+	Private   bool
+	Captcha   bool // the captcha is shown instead of the profile
+	Verified  bool
+	Followers int
+	Videos    []fakeVideo
+}
+
+// user returns the user and stats objects TikTok embeds in the page of p.
+func (p fakeProfile) user() (map[string]any, map[string]any) {
+	return map[string]any{
+		"uniqueId":       p.UniqueID,
+		"nickname":       p.Nickname,
+		"signature":      p.Bio,
+		"avatarLarger":   "https://p16-sign.tiktokcdn.com/" + p.UniqueID + ".jpeg",
+		"verified":       p.Verified,
+		"privateAccount": p.Private,
+		"region":         "US",
+		"language":       "en",
+		"bioLink":        map[string]any{"link": "linktr.ee/" + p.UniqueID, "risk": 0},
+	}, map[string]any{
+		"followerCount":  p.Followers,
+		"followingCount": 12,
+		"heart":          p.Followers * 10,
+		"heartCount":     p.Followers * 10,
+		"videoCount":     len(p.Videos),
+	}
+}
+
+// FollowersText returns the follower count of p the way TikTok shows it, like "987", "12.3K" and "1.2M".
+func (p fakeProfile) FollowersText() string {
+	switch {
+	case p.Followers >= 1_000_000:
+		return strconv.FormatFloat(float64(p.Followers)/1_000_000, 'f', -1, 64) + "M"
+	case p.Followers >= 1_000:
+		return strconv.FormatFloat(float64(p.Followers)/1_000, 'f', -1, 64) + "K"
+	}
+	return strconv.Itoa(p.Followers)
+}
+
+// State returns the content of the script __UNIVERSAL_DATA_FOR_REHYDRATION__ of the page of p.
+func (p fakeProfile) State() template.JS {
+	user, stats := p.user()
+	data, _ := json.Marshal(map[string]any{
+		"__DEFAULT_SCOPE__": map[string]any{
+			"webapp.user-detail": map[string]any{
+				"userInfo": map[string]any{"user": user, "stats": stats},
+			},
+		},
+	})
+	return template.JS(data)
+}
+
+// SIGIState returns the content of the script SIGI_STATE of the page of p.
+func (p fakeProfile) SIGIState() template.JS {
+	user, stats := p.user()
+	data, _ := json.Marshal(map[string]any{
+		"UserModule": map[string]any{
+			"users": map[string]any{p.UniqueID: user},
+			"stats": map[string]any{p.UniqueID: stats},
+		},
+	})
+	return template.JS(data)
+}
+
+// fakeTikTok serves profile pages built from the templates in testdata/fake_tiktok, mimicking what TikTok shows a new visitor: the first profile page of a browser comes with the login modal and the refresh button, later ones with the video grid right away.
+//
+// Profiles not in profiles get the "Couldn't find this account" page. /search/user and /search/video list the profiles (and their videos) whose unique IDs, nicknames or bios contain the query, private profiles have no videos listed. /tag/<name> pages fetch the videos of the public profiles whose bios contain #<name> from /api/challenge/item_list/, two at a time as they are scrolled. Profile pages fetch their videos from /api/post/item_list/ once the grid is shown, with the same statistics as fakeAPIServer.
+func fakeTikTok(t *testing.T, profiles ...fakeProfile) *httptest.Server {
+	t.Helper()
+	tmpl := template.Must(template.ParseGlob("testdata/fake_tiktok/*.html"))
+	byID := make(map[string]fakeProfile)
+	for _, p := range profiles {
+		byID[p.UniqueID] = p
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tab, ok := strings.CutPrefix(r.URL.Path, "/search/"); ok && (tab == "user" || tab == "video") {
+			query := strings.ToLower(r.URL.Query().Get("q"))
+			var found []fakeProfile
+			for _, p := range profiles {
+				if p.Captcha || tab == "video" && p.Private {
+					continue
+				}
+				if strings.Contains(strings.ToLower(p.UniqueID+" "+p.Nickname+" "+p.Bio), query) {
+					found = append(found, p)
+				}
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			tmpl.ExecuteTemplate(w, "search_"+tab+".html", struct {
+				Query    string
+				Profiles []fakeProfile
+			}{query, found})
+			return
+		}
+
+		if name, ok := strings.CutPrefix(r.URL.Path, "/tag/"); ok {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			tmpl.ExecuteTemplate(w, "tag.html", name)
+			return
+		}
+		if r.URL.Path == challengeItemListPath {
+			tag := "#" + strings.ToLower(r.URL.Query().Get("challengeName"))
+			var items []map[string]any
+			for _, p := range profiles {
+				if p.Captcha || p.Private || !strings.Contains(strings.ToLower(p.Bio), tag) {
+					continue
+				}
+				for _, v := range p.Videos {
+					items = append(items, map[string]any{
+						"id":         strconv.Itoa(v.ID),
+						"desc":       p.Bio,
+						"createTime": v.ID * 1000,
+						"author":     map[string]any{"id": "id-" + p.UniqueID, "uniqueId": p.UniqueID, "nickname": p.Nickname, "signature": p.Bio},
+						"authorStats": map[string]any{
+							"followerCount": p.Followers,
+							"heartCount":    p.Followers * 10,
+							"videoCount":    len(p.Videos),
+						},
+					})
+				}
+			}
+			cursor, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+			count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+			cursor = min(cursor, len(items))
+			end := min(cursor+max(count, 1), len(items))
+			json.NewEncoder(w).Encode(map[string]any{
+				"itemList": items[cursor:end],
+				"cursor":   strconv.Itoa(end),
+				"hasMore":  end < len(items),
+			})
+			return
+		}
+
+		if r.URL.Path == postItemListPath {
+			var items []map[string]any
+			for _, v := range byID[r.URL.Query().Get("uniqueId")].Videos {
+				items = append(items, map[string]any{
+					"id":           strconv.Itoa(v.ID),
+					"desc":         fmt.Sprintf("video %d #ugc", v.ID),
+					"createTime":   v.ID * 1000,
+					"isPinnedItem": v.Pinned,
+					"author":       map[string]any{"uniqueId": r.URL.Query().Get("uniqueId")},
+					"stats": map[string]any{
+						"diggCount":    v.ID * 10,
+						"playCount":    v.ID * 100,
+						"commentCount": v.ID,
+						"shareCount":   1,
+						"collectCount": v.ID * 2,
+					},
+					"video":     map[string]any{"duration": v.ID + 10},
+					"music":     map[string]any{"title": "original sound", "authorName": "fake"},
+					"textExtra": []map[string]any{{"hashtagName": "ugc"}},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"itemList": items, "cursor": "0", "hasMore": false})
+			return
+		}
+
+		uniqueID, ok := strings.CutPrefix(r.URL.Path, "/@")
+		if !ok || strings.Contains(uniqueID, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		p, ok := byID[uniqueID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			tmpl.ExecuteTemplate(w, "not_found.html", nil)
+			return
+		}
+		if p.Captcha {
+			tmpl.ExecuteTemplate(w, "captcha.html", nil)
+			return
+		}
+		if p.Private {
+			tmpl.ExecuteTemplate(w, "private.html", p)
+			return
+		}
+
+		_, err := r.Cookie("tt_visited")
+		firstVisit := err != nil
+		http.SetCookie(w, &http.Cookie{Name: "tt_visited", Value: "1", Path: "/"})
+		tmpl.ExecuteTemplate(w, "profile.html", struct {
+			fakeProfile
+			Base          string
+			LoginModal    bool
+			RefreshButton bool
+		}{p, srv.URL, firstVisit, firstVisit})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// fakeAPIServer serves the /api?url= contract of the API server. Statistics are derived from the video ID in the link: the video is created at ID*1000 (Unix time), played ID*100 times, liked ID*10 times, commented on ID times and shared once.
+func fakeAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(path.Base(r.URL.Query().Get("url")))
+		if err != nil {
+			w.Write([]byte(`{}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"create_time": id * 1000,
+			"desc":        fmt.Sprintf("video %d #ugc", id),
+			"duration":    id + 10,
+			"hashtags":    []string{"ugc"},
+			"music":       map[string]string{"title": "original sound", "author": "fake"},
+			"statistics": map[string]int{
+				"digg_count":    id * 10,
+				"play_count":    id * 100,
+				"comment_count": id,
+				"share_count":   1,
+				"collect_count": id * 2,
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestFakeTikTok(t *testing.T) {
+	site := fakeTikTok(t, fakeProfile{UniqueID: "alice", Bio: "#UGC", Videos: []fakeVideo{{ID: 1, Pinned: true}, {ID: 2}}}, fakeProfile{UniqueID: "carol", Private: true})
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	get := func(p string) (int, string) {
+		resp, err := client.Get(site.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	_, first := get("/@alice")
+	if !strings.Contains(first, `id="login-modal-title"`) || !strings.Contains(first, "<main>") {
+		t.Error("first visit does not come with the login modal and the refresh button")
+	}
+	_, second := get("/@alice")
+	if strings.Contains(second, `id="login-modal-title"`) || !strings.Contains(second, site.URL+"/@alice/video/2") || !strings.Contains(second, "video-card-badge") {
+		t.Error("second visit does not come with the video grid")
+	}
+	if _, body := get("/@carol"); !strings.Contains(body, "This account is private") {
+		t.Error("private profile is not private")
+	}
+	if code, body := get("/@ghost"); code != http.StatusNotFound || !strings.Contains(body, "Couldn't find this account") {
+		t.Error("unknown profile is found")
+	}
+	if _, body := get("/search/user?q=ALI"); !strings.Contains(body, `data-e2e="search-user-unique-id">alice<`) || strings.Contains(body, "carol") {
+		t.Error("user search does not find alice only")
+	}
+	if _, body := get("/search/video?q="); !strings.Contains(body, "/@alice/video/2") || strings.Contains(body, "carol") {
+		t.Error("video search does not list videos of public profiles only")
+	}
+	if _, body := get(challengeItemListPath + "?challengeName=ugc&count=1&cursor=1"); !strings.Contains(body, `"id":"2"`) || !strings.Contains(body, `"hasMore":false`) {
+		t.Errorf("item list = %s, want the second video of alice only", body)
+	}
+	if _, body := get(postItemListPath + "?uniqueId=alice"); !strings.Contains(body, `"isPinnedItem":true`) || !strings.Contains(body, `"playCount":200`) {
+		t.Errorf("post item list = %s, want both videos of alice with stats", body)
+	}
+}
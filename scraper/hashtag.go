@@ -0,0 +1,101 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// CollectHashtags collects posts from the pages of tags, up to postsPerTag posts per tag, and saves them in output (see fileopers.SaveHashtagResults) in the shape ugcinfo.FromJSON reads, so that they can be scraped with Scrape.
+//
+// Posts collected so far are saved whether the process has finished successfully or not, including when it is interrupted by SIGINT or SIGTERM.
+func CollectHashtags(tags []string, output string) (err error) {
+	ctx, stop := notifyContext(context.Background())
+	defer stop()
+
+	var posts ugcinfo.HashtagResults
+	defer func() {
+		if _, serr := fileopers.SaveHashtagResults(posts, output); serr != nil {
+			err = errors.Join(err, serr)
+		}
+	}()
+
+	allocCtx, cancel := newAllocator(ctx)
+	defer cancel()
+	browserCtx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
+	defer cancel()
+	if err := chromedp.Run(browserCtx); err != nil {
+		return err
+	}
+	if err := prepareBrowser(browserCtx); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool) // IDs of posts collected, as a post may have several of tags.
+	for _, tag := range tags {
+		items, err := collectHashtag(browserCtx, tag)
+		for _, item := range items {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+			posts = append(posts, ugcinfo.HashtagResult{
+				Author:      item.Author,
+				AuthorStats: item.AuthorStats,
+				CreatedTime: int(item.CreateTime),
+				Desc:        item.Desc,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("collecting #%s: %w", tag, err)
+		}
+		log.Println(len(items), "posts collected from #"+tag)
+	}
+
+	return nil
+}
+
+// collectHashtag collects up to postsPerTag posts from the page of tag in a new tab of the browser of browserCtx. Posts collected before an error are returned along with it.
+//
+// The posts are read from the item lists the page fetches while it is scrolled, which carry the authors and their stats.
+func collectHashtag(browserCtx context.Context, tag string) ([]tiktokItem, error) {
+	ctx, cancel := chromedp.NewContext(browserCtx)
+	defer cancel()
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(prepareTab)); err != nil { // opens the tab.
+		return nil, err
+	}
+	var c itemCollector
+	c.listen(ctx, challengeItemListPath)
+
+	u := TIKTOK_TAG + url.PathEscape(strings.TrimPrefix(tag, "#"))
+	if verbose {
+		log.Println("Collecting posts from", u)
+	}
+	err := chromedp.Run(
+		ctx,
+		navigate(u),
+		chromedp.WaitReady(`//body`),
+	)
+	if err == nil {
+		err = getPastChallenges(ctx, u)
+	}
+	if err == nil {
+		err = scrollFor(ctx, u, int(postsPerTag), func() (int, error) { return c.len(), nil })
+	}
+	items := c.collected()
+	if len(items) > int(postsPerTag) {
+		items = items[:postsPerTag]
+	}
+	if err == nil && len(items) == 0 {
+		log.Println("No posts found on", u)
+	}
+
+	return items, err
+}
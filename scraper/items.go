@@ -0,0 +1,240 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// Paths of the APIs TikTok pages fetch item lists from.
+const (
+	challengeItemListPath = "/api/challenge/item_list/"
+	postItemListPath      = "/api/post/item_list/" // videos on profile pages
+)
+
+// flexInt is an integer which TikTok sends either as a number or as a string.
+type flexInt int
+
+func (n *flexInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*n = flexInt(i)
+
+	return nil
+}
+
+// tiktokItem is a post in the item lists TikTok pages fetch.
+type tiktokItem struct {
+	ID          string                           `json:"id"`
+	Desc        string                           `json:"desc"`
+	CreateTime  flexInt                          `json:"createTime"`
+	Author      ugcinfo.HashtagResultAuthor      `json:"author"`
+	AuthorStats ugcinfo.HashtagResultAuthorStats `json:"authorStats"`
+	Stats       tiktokItemStats                  `json:"stats"`
+	Video       tiktokItemVideo                  `json:"video"`
+	Music       tiktokItemMusic                  `json:"music"`
+	TextExtra   []tiktokTextExtra                `json:"textExtra"` // hashtags and mentions in Desc
+}
+
+// tiktokItemStats is the statistics of a post in the item lists.
+type tiktokItemStats struct {
+	DiggCount    flexInt `json:"diggCount"`
+	PlayCount    flexInt `json:"playCount"`
+	CommentCount flexInt `json:"commentCount"`
+	ShareCount   flexInt `json:"shareCount"`
+	CollectCount flexInt `json:"collectCount"`
+}
+
+// tiktokItemVideo is the video of a post in the item lists.
+type tiktokItemVideo struct {
+	Duration flexInt `json:"duration"` // in seconds
+}
+
+// tiktokItemMusic is the sound of a post in the item lists.
+type tiktokItemMusic struct {
+	Title      string `json:"title"`
+	AuthorName string `json:"authorName"`
+}
+
+// tiktokTextExtra is a hashtag or a mention in the description of a post, only hashtags have names.
+type tiktokTextExtra struct {
+	HashtagName string `json:"hashtagName"`
+}
+
+// videoStats returns the statistics of item, which is the video at link.
+func (item tiktokItem) videoStats(link string) ugcinfo.VideoStats {
+	var hashtags []string
+	for _, extra := range item.TextExtra {
+		if extra.HashtagName != "" {
+			hashtags = append(hashtags, extra.HashtagName)
+		}
+	}
+	return ugcinfo.VideoStats{
+		URL:          link,
+		ID:           item.ID,
+		CreateTime:   time.Unix(int64(item.CreateTime), 0),
+		PlayCount:    int(item.Stats.PlayCount),
+		DiggCount:    int(item.Stats.DiggCount),
+		CommentCount: int(item.Stats.CommentCount),
+		ShareCount:   int(item.Stats.ShareCount),
+		CollectCount: int(item.Stats.CollectCount),
+		Duration:     int(item.Video.Duration),
+		Description:  item.Desc,
+		Hashtags:     hashtags,
+		MusicTitle:   item.Music.Title,
+		MusicAuthor:  item.Music.AuthorName,
+	}
+}
+
+// itemList is a response of the item list APIs.
+type itemList struct {
+	ItemList []tiktokItem `json:"itemList"`
+}
+
+// itemCollector collects the items in the item lists a page fetches while it is scrolled, without duplicates.
+type itemCollector struct {
+	mu      sync.Mutex
+	ids     map[string]int // indexes in items by IDs
+	items   []tiktokItem
+	loading int // item lists received but not collected yet
+}
+
+// listen makes c collect the items in responses to requests whose paths contain path in the tab of ctx. It must be called before navigating.
+func (c *itemCollector) listen(ctx context.Context, path string) {
+	var mu sync.Mutex
+	pending := make(map[network.RequestID]bool) // matching requests whose bodies are not loaded yet.
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *network.EventResponseReceived:
+			if strings.Contains(ev.Response.URL, path) {
+				mu.Lock()
+				pending[ev.RequestID] = true
+				mu.Unlock()
+			}
+		case *network.EventLoadingFinished:
+			mu.Lock()
+			ok := pending[ev.RequestID]
+			delete(pending, ev.RequestID)
+			mu.Unlock()
+			if !ok {
+				return
+			}
+			c.mu.Lock()
+			c.loading++
+			c.mu.Unlock()
+			go func() { // commands cannot be sent in listeners.
+				defer func() {
+					c.mu.Lock()
+					c.loading--
+					c.mu.Unlock()
+				}()
+				body, err := network.GetResponseBody(ev.RequestID).Do(tabExecutor(ctx))
+				if err != nil {
+					if verbose {
+						log.Println("Failed to get item list:", err)
+					}
+					return
+				}
+				var list itemList
+				if err := json.Unmarshal(body, &list); err != nil {
+					if verbose {
+						log.Println("Failed to parse item list:", err)
+					}
+					return
+				}
+				c.add(list.ItemList)
+			}()
+		}
+	})
+}
+
+// add adds items which are not collected yet.
+func (c *itemCollector) add(items []tiktokItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ids == nil {
+		c.ids = make(map[string]int)
+	}
+	for _, item := range items {
+		if _, ok := c.ids[item.ID]; item.ID == "" || ok {
+			continue
+		}
+		c.ids[item.ID] = len(c.items)
+		c.items = append(c.items, item)
+	}
+}
+
+// remove removes the items collected for which drop returns true.
+func (c *itemCollector) remove(drop func(tiktokItem) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := c.items[:0]
+	for _, item := range c.items {
+		if drop(item) {
+			delete(c.ids, item.ID)
+			continue
+		}
+		c.ids[item.ID] = len(kept)
+		kept = append(kept, item)
+	}
+	clear(c.items[len(kept):]) // lets the removed items be garbage collected.
+	c.items = kept
+}
+
+// item returns the item collected with id.
+func (c *itemCollector) item(id string) (tiktokItem, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, ok := c.ids[id]
+	if !ok {
+		return tiktokItem{}, false
+	}
+	return c.items[i], true
+}
+
+// settle waits up to timeout for the item lists already received to be collected.
+func (c *itemCollector) settle(ctx context.Context, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		c.mu.Lock()
+		loading := c.loading
+		c.mu.Unlock()
+		if loading == 0 || time.Now().After(deadline) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// len returns the number of items collected.
+func (c *itemCollector) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// collected returns a copy of the items collected.
+func (c *itemCollector) collected() []tiktokItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]tiktokItem(nil), c.items...)
+}
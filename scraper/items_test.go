@@ -0,0 +1,32 @@
+package scraper
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestItemList(t *testing.T) {
+	data, err := os.ReadFile("testdata/item_list/challenge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list itemList
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+
+	var c itemCollector
+	c.add(list.ItemList)
+	c.add(list.ItemList[1:]) // fetched again after scrolling back.
+	items := c.collected()
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if alice := items[0]; alice.CreateTime != 1700000000 || alice.Author.UniqueID != "alice" || alice.AuthorStats.FollowerCount != 12300 || alice.Desc != "morning routine #skincare #ugc" {
+		t.Errorf("items[0] = %+v", alice)
+	}
+	if bob := items[1]; bob.CreateTime != 1700000100 { // createTime as a string
+		t.Errorf("items[1].CreateTime = %d, want 1700000100", bob.CreateTime)
+	}
+}
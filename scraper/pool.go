@@ -0,0 +1,234 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// job is a UGC to be processed by a tab. ugc is a copy, so the tab is free to change it.
+type job struct {
+	index int // index in the ugcs of the pool
+	ugc   ugcinfo.UGCInfo
+}
+
+// profileResult is what a tab found on the profile page of a UGC. If finished is false, AP and AI are still being calculated and a statsResult will follow.
+type profileResult struct {
+	index    int
+	ugc      ugcinfo.UGCInfo
+	finished bool
+}
+
+// statsResult is the AP and AI of a UGC calculated from the API along with the videos sampled, or the error which prevented it.
+type statsResult struct {
+	index  int
+	videos []ugcinfo.VideoStats
+	ap     int
+	ai     float32
+	err    error
+}
+
+// pool runs the tabs and the API goroutines of scrapeProfileVideos.
+//
+// Workers never touch the UGCs being scraped. They work on copies and send what they found over channels to run, which is the only one reading and writing the UGCs and the journal.
+type pool struct {
+	openTab func(browserCtx context.Context, tab int) (context.Context, context.CancelFunc, error)                 // opens a new tab, see openTab
+	scrape  func(ctxTab context.Context, ugc *ugcinfo.UGCInfo, first *bool) ([]string, error)                      // scrapes a profile in a tab, see scrapeProfile
+	stats   func(ctx context.Context, links []string) (videos []ugcinfo.VideoStats, ap int, ai float32, err error) // calculates AP and AI, see calculateAPAndAI
+	failed  func(ctxTab context.Context, ugc ugcinfo.UGCInfo, err error)                                           // called with failed UGCs, see saveFailure
+
+	jobs   chan job
+	apiCtx context.Context // context of API requests, which may outlive the tabs for a while, see shutdownGrace.
+	wg     sync.WaitGroup  // tabs and API goroutines
+
+	profiles chan profileResult
+	results  chan statsResult
+	errs     chan error // errors that stop the whole process
+}
+
+// newPool returns a pool opening tabs with openTab and scraping profiles with scrape.
+func newPool(openTab func(context.Context, int) (context.Context, context.CancelFunc, error), scrape func(context.Context, *ugcinfo.UGCInfo, *bool) ([]string, error)) *pool {
+	return &pool{
+		openTab: openTab,
+		scrape:  scrape,
+		stats: func(ctx context.Context, links []string) ([]ugcinfo.VideoStats, int, float32, error) {
+			return calculateAPAndAI(ctx, statsProvider, links)
+		},
+		failed:   saveFailure,
+		profiles: make(chan profileResult),
+		results:  make(chan statsResult),
+		errs:     make(chan error),
+	}
+}
+
+// run scrapes ugcs in tabs opened in the browser of browserCtx and records every finished UGC in journal, which may be nil. It returns once all the workers have exited, so ugcs is no longer touched by then.
+//
+// When ctxParent is canceled, tabs stop taking new UGCs while in-flight API requests are given shutdownGrace to finish, and an error is returned. The first error that stops the whole process stops all the workers and is returned.
+func (p *pool) run(ctxParent, browserCtx context.Context, ugcs *[]ugcinfo.UGCInfo, journal *fileopers.Journal) error {
+	numTabs := int(tabs) // number of tabs working at the same time.
+	if numTabs < 1 {
+		numTabs = 1
+	}
+	if numTabs > len(*ugcs) {
+		numTabs = len(*ugcs)
+	}
+
+	p.jobs = make(chan job, len(*ugcs)) // shared queue of ugcs to be processed.
+	for i, ugc := range *ugcs {
+		p.jobs <- job{index: i, ugc: ugc}
+	}
+	close(p.jobs)
+
+	tabsCtx, cancelTabs := context.WithCancel(browserCtx)
+	defer cancelTabs()
+	var cancelAPI context.CancelFunc
+	p.apiCtx, cancelAPI = context.WithCancel(context.WithoutCancel(ctxParent))
+	defer cancelAPI()
+
+	for t := 0; t < numTabs; t++ { // starts tabs
+		p.wg.Add(1)
+		go func(tab int) {
+			defer p.wg.Done()
+			if err := p.scrapeInTab(tabsCtx, tab); err != nil && tabsCtx.Err() == nil {
+				p.errs <- err
+			}
+		}(t)
+	}
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	var fatal error
+	stop := func(err error) { // stops all the workers because of err.
+		if fatal == nil {
+			fatal = err
+			cancelTabs()
+			cancelAPI()
+		}
+	}
+	record := func(index int) {
+		if err := journal.Record((*ugcs)[index]); err != nil { // the UGC is scraped again on resume, not worth stopping for.
+			log.Println("Failed to journal", (*ugcs)[index].UniqueID+":", err)
+		}
+	}
+	canceled := ctxParent.Done()
+	var grace <-chan time.Time
+	for {
+		select {
+		case r := <-p.profiles:
+			(*ugcs)[r.index] = r.ugc
+			if r.finished {
+				record(r.index)
+			}
+		case r := <-p.results:
+			ugc := &(*ugcs)[r.index]
+			switch {
+			case r.err != nil:
+				err := fmt.Errorf("%w: %w", errAPI, r.err)
+				markFailed(ugc, err)
+				p.failed(nil, *ugc, err) // the tab has moved on, so there is no page to save.
+			case len(r.videos) == 0 && ugc.VideoCount != 0: // AP 0 would look like a profile nobody watches.
+				markFailed(ugc, errNoVideos)
+				p.failed(nil, *ugc, errNoVideos)
+			default:
+				ugc.AP = r.ap
+				ugc.AI = r.ai
+				ugc.LatestVideoTime = time.Unix(0, 0)
+				if len(r.videos) != 0 {
+					ugc.LatestVideoTime = r.videos[0].CreateTime
+				}
+				ugc.VideosStats = r.videos
+				ugc.Status = ugcinfo.StatusOK
+			}
+			record(r.index)
+		case err := <-p.errs:
+			stop(err)
+		case <-canceled: // tabs are closed along with ctxParent, but in-flight API requests get a chance to finish.
+			log.Println("Canceled, waiting up to", shutdownGrace, "for in-flight API requests")
+			canceled = nil
+			grace = time.After(shutdownGrace)
+		case <-grace:
+			cancelAPI()
+			grace = nil
+		case <-done:
+			if fatal != nil {
+				return fatal
+			}
+			if ctxParent.Err() != nil {
+				return errors.New("canceled")
+			}
+			return nil
+		}
+	}
+}
+
+// scrapeInTab opens a new tab in the browser of browserCtx and processes the jobs of p until they are drained.
+//
+// Emails are found in the tab directly while AP and AI are calculated in API goroutines, whose requests are limited by the flow control in [utils]. Failures of a single UGC are recorded in its Status and ErrorMessage and do not stop the tab. An error is only returned when the tab itself is no longer usable.
+func (p *pool) scrapeInTab(browserCtx context.Context, tab int) error {
+	ctx, cancel, err := p.openTab(browserCtx, tab)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	first := true
+	challenges := 0 // challenges in a row
+	for j := range p.jobs {
+		if ctx.Err() != nil { // the tab is closed, leaves the rest unscraped.
+			return ctx.Err()
+		}
+		if verbose {
+			log.Printf("[tab %d] Processing the %dth user: %s", tab, j.index+1, j.ugc.UniqueID)
+		}
+		ugc := j.ugc
+		links, err := p.scrape(ctx, &ugc, &first)
+		if err != nil {
+			if ctx.Err() != nil { // the tab itself is gone, so there is no point going on.
+				return ctx.Err()
+			}
+			markFailed(&ugc, err)
+			p.failed(ctx, ugc, err) // saves the page before the tab moves on.
+			forgetProfile(ugc.UniqueID)
+			p.profiles <- profileResult{index: j.index, ugc: ugc, finished: true}
+			if isChallenge(err) { // backs off, hoping TikTok calms down.
+				challenges++
+				wait := challengeBackoffFor(challenges)
+				log.Printf("[tab %d] Backing off for %s", tab, wait)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+			continue
+		}
+		challenges = 0
+
+		p.profiles <- profileResult{index: j.index, ugc: ugc}
+		p.wg.Add(1) // the tab is still counted in p.wg, so p.wg cannot have reached zero.
+		go p.fetchStats(j.index, ugc.UniqueID, links)
+	}
+
+	return nil
+}
+
+// fetchStats calculates AP and AI from links of the profile of uniqueID and sends them to p.results. Nothing is sent if p.apiCtx is canceled, leaving the UGC unscraped.
+func (p *pool) fetchStats(index int, uniqueID string, links []string) {
+	defer p.wg.Done()
+	log.Printf("Getting AP and AI of the %dth user\n", index+1)
+	videos, ap, ai, err := p.stats(p.apiCtx, links)
+	forgetProfile(uniqueID)
+	if p.apiCtx.Err() != nil { // canceled after the grace period.
+		return
+	}
+	p.results <- statsResult{index: index, videos: videos, ap: ap, ai: ai, err: err}
+}
@@ -0,0 +1,231 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// The tests in this file run the pool with fake tabs, so that they can run without Chrome, e.g. under the race detector with go test -race.
+
+// fakeOpenTab opens tabs that are merely contexts.
+func fakeOpenTab(browserCtx context.Context, tab int) (context.Context, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(browserCtx)
+	return ctx, cancel, nil
+}
+
+// errBroken is returned by fakeScrape for unique IDs starting with "broken".
+var errBroken = errors.New("broken page")
+
+// fakeScrape scrapes the profile of ugc without a browser. Profiles of unique IDs starting with "ghost" are not found, those starting with "broken" fail with errBroken, the others have two videos whose IDs are the number after "user", starting from 1.
+func fakeScrape(ctxTab context.Context, ugc *ugcinfo.UGCInfo, first *bool) ([]string, error) {
+	if strings.HasPrefix(ugc.UniqueID, "ghost") {
+		return nil, errProfileNotFound
+	}
+	if strings.HasPrefix(ugc.UniqueID, "broken") {
+		return nil, fmt.Errorf("reading %s: %w", ugc.UniqueID, errBroken)
+	}
+	var n int
+	fmt.Sscanf(ugc.UniqueID, "user%d", &n)
+	ugc.Name = strings.ToUpper(ugc.UniqueID)
+	ugc.Email = append(ugc.Email, "<"+ugc.UniqueID+"@example.com>")
+	*first = false
+	return []string{
+		fmt.Sprintf("https://www.tiktok.com/@%s/video/%d", ugc.UniqueID, n),
+		fmt.Sprintf("https://www.tiktok.com/@%s/video/%d", ugc.UniqueID, n+1),
+	}, nil
+}
+
+// usePool sets the variables used by the pool for the test: tabs tabs, the fake API server and a journal in a temporary working directory.
+func usePool(t *testing.T, numTabs uint) *fileopers.Journal {
+	t.Helper()
+	oldTabs := tabs
+	t.Cleanup(func() { tabs = oldTabs })
+	tabs = numTabs
+
+	u, err := url.Parse(fakeAPIServer(t).URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.SetAPIServer(u)
+
+	fileopers.SetWorkingDir(t.TempDir())
+	journal, err := fileopers.OpenJournal("posts.json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { journal.Close() })
+
+	return journal
+}
+
+func TestPool(t *testing.T) {
+	journal := usePool(t, 4)
+	var ugcs []ugcinfo.UGCInfo
+	for i := 1; i <= 40; i++ {
+		id := fmt.Sprintf("user%d", i)
+		if i%10 == 0 {
+			id = fmt.Sprintf("ghost%d", i)
+		}
+		if i%10 == 5 {
+			id = fmt.Sprintf("broken%d", i)
+		}
+		ugcs = append(ugcs, ugcinfo.UGCInfo{UniqueID: id, Status: ugcinfo.StatusTimeout}) // as if resumed.
+	}
+
+	ctx := context.Background()
+	if err := newPool(fakeOpenTab, fakeScrape).run(ctx, ctx, &ugcs, journal); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, ugc := range ugcs {
+		n := i + 1
+		if n%10 == 0 {
+			if ugc.Status != ugcinfo.StatusNotFound {
+				t.Errorf("ugcs[%d] = %+v, want status not_found", i, ugc)
+			}
+			continue
+		}
+		if n%10 == 5 {
+			if ugc.Status != ugcinfo.StatusError {
+				t.Errorf("ugcs[%d] = %+v, want status error", i, ugc)
+			}
+			continue
+		}
+		if ugc.Status != ugcinfo.StatusOK || ugc.Name != strings.ToUpper(ugc.UniqueID) || len(ugc.Email) != 1 {
+			t.Errorf("ugcs[%d] = %+v, want status ok with the name and the email", i, ugc)
+		}
+		if ap := (2*n + 1) * 50; ugc.AP != ap || ugc.LatestVideoTime.Unix() != int64(n*1000) {
+			t.Errorf("ugcs[%d] = %+v, want AP %d and latest video time %d", i, ugc, ap, n*1000)
+		}
+		if v := ugc.VideosStats; len(v) != 2 || v[0].ID != strconv.Itoa(n) || v[1].PlayCount != (n+1)*100 || v[1].CommentCount != n+1 {
+			t.Errorf("ugcs[%d].VideosStats = %+v, want videos %d and %d", i, v, n, n+1)
+		}
+	}
+
+	journaled, err := fileopers.ReadJournal("posts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journaled) != len(ugcs) {
+		t.Errorf("%d UGCs journaled, want %d", len(journaled), len(ugcs))
+	}
+	if j := journaled["user1"]; j.Status != ugcinfo.StatusOK || j.AP != 150 {
+		t.Errorf("journaled user1 = %+v, want the final result", j)
+	}
+
+	failures, err := fileopers.ReadFailures() // only the broken ones are failures, not found profiles are not.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 4 {
+		t.Fatalf("%d failures saved, want 4", len(failures))
+	}
+	for _, f := range failures {
+		if !strings.HasPrefix(f.UniqueID, "broken") || f.Status != string(ugcinfo.StatusError) || len(f.Errors) != 2 || f.Errors[1] != errBroken.Error() || f.ErrorFile == "" {
+			t.Errorf("failure = %+v", f)
+		}
+	}
+}
+
+func TestPoolZeroPlays(t *testing.T) {
+	journal := usePool(t, 1)
+	ugcs := []ugcinfo.UGCInfo{{UniqueID: "user0"}, {UniqueID: "user2"}} // video 0 has no plays.
+
+	ctx := context.Background()
+	if err := newPool(fakeOpenTab, fakeScrape).run(ctx, ctx, &ugcs, journal); err != nil {
+		t.Fatal(err)
+	}
+	if u := ugcs[0]; u.Status != ugcinfo.StatusOK || u.AP != 50 || u.AI != 0.1 {
+		t.Errorf("ugcs[0] = %+v, want AP 50 and the AI of video 1 only", u)
+	}
+	if u := ugcs[1]; u.Status != ugcinfo.StatusOK || u.AP != 250 {
+		t.Errorf("ugcs[1] = %+v, want it scraped too", u)
+	}
+	journaled, err := fileopers.ReadJournal("posts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journaled) != 2 {
+		t.Errorf("%d UGCs journaled, want 2", len(journaled))
+	}
+}
+
+func TestPoolNoVideos(t *testing.T) {
+	journal := usePool(t, 1)
+	ugcs := []ugcinfo.UGCInfo{{UniqueID: "empty"}, {UniqueID: "unloaded"}}
+	scrape := func(ctxTab context.Context, ugc *ugcinfo.UGCInfo, first *bool) ([]string, error) {
+		if ugc.UniqueID == "unloaded" { // as if no video links were found on a profile with videos.
+			ugc.VideoCount = 3
+		}
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	if err := newPool(fakeOpenTab, scrape).run(ctx, ctx, &ugcs, journal); err != nil {
+		t.Fatal(err)
+	}
+	if u := ugcs[0]; u.Status != ugcinfo.StatusOK || u.AP != 0 {
+		t.Errorf("ugcs[0] = %+v, want status ok for a profile without videos", u)
+	}
+	if u := ugcs[1]; u.Status != ugcinfo.StatusError || u.ErrorMessage != errNoVideos.Error() {
+		t.Errorf("ugcs[1] = %+v, want status error for a profile whose videos were not read", u)
+	}
+}
+
+func TestPoolTabError(t *testing.T) {
+	journal := usePool(t, 3)
+	ugcs := make([]ugcinfo.UGCInfo, 30)
+	for i := range ugcs {
+		ugcs[i].UniqueID = fmt.Sprintf("user%d", i+1)
+	}
+	errBrokenTab := errors.New("broken tab")
+	var opened atomic.Int32
+	openTab := func(browserCtx context.Context, tab int) (context.Context, context.CancelFunc, error) {
+		if opened.Add(1) == 3 {
+			return nil, nil, errBrokenTab
+		}
+		return fakeOpenTab(browserCtx, tab)
+	}
+
+	ctx := context.Background()
+	if err := newPool(openTab, fakeScrape).run(ctx, ctx, &ugcs, journal); !errors.Is(err, errBrokenTab) {
+		t.Errorf("run() = %v, want %v", err, errBrokenTab)
+	}
+}
+
+func TestPoolCanceled(t *testing.T) {
+	journal := usePool(t, 1)
+	ugcs := make([]ugcinfo.UGCInfo, 10)
+	for i := range ugcs {
+		ugcs[i].UniqueID = fmt.Sprintf("user%d", i+1)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scrape := func(ctxTab context.Context, ugc *ugcinfo.UGCInfo, first *bool) ([]string, error) {
+		if ugc.UniqueID == "user4" { // as if SIGINT is sent while the tab is on the 4th profile.
+			cancel()
+			<-ctxTab.Done()
+			return nil, ctxTab.Err()
+		}
+		return fakeScrape(ctxTab, ugc, first)
+	}
+
+	if err := newPool(fakeOpenTab, scrape).run(ctx, ctx, &ugcs, journal); err == nil {
+		t.Error("run() = nil, want an error")
+	}
+	for i, ugc := range ugcs {
+		if done := ugc.Status == ugcinfo.StatusOK; done != (i < 3) {
+			t.Errorf("ugcs[%d].Status = %q", i, ugc.Status)
+		}
+	}
+}
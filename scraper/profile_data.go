@@ -0,0 +1,194 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// tiktokUser is the user object embedded in TikTok pages.
+type tiktokUser struct {
+	UniqueID       string `json:"uniqueId"`
+	Nickname       string `json:"nickname"`
+	Signature      string `json:"signature"`
+	AvatarLarger   string `json:"avatarLarger"`
+	AvatarMedium   string `json:"avatarMedium"`
+	Verified       bool   `json:"verified"`
+	PrivateAccount bool   `json:"privateAccount"`
+	Region         string `json:"region"`
+	Language       string `json:"language"`
+	BioLink        struct {
+		Link string `json:"link"`
+	} `json:"bioLink"`
+}
+
+// tiktokUserStats is the user stats object embedded in TikTok pages.
+type tiktokUserStats struct {
+	FollowerCount  int `json:"followerCount"`
+	FollowingCount int `json:"followingCount"`
+	Heart          int `json:"heart"`
+	HeartCount     int `json:"heartCount"`
+	VideoCount     int `json:"videoCount"`
+}
+
+// universalData represents the script __UNIVERSAL_DATA_FOR_REHYDRATION__ of a profile page.
+type universalData struct {
+	DefaultScope struct {
+		UserDetail struct {
+			UserInfo struct {
+				User  *tiktokUser      `json:"user"`
+				Stats *tiktokUserStats `json:"stats"`
+			} `json:"userInfo"`
+		} `json:"webapp.user-detail"`
+	} `json:"__DEFAULT_SCOPE__"`
+}
+
+// sigiState represents the script SIGI_STATE of a profile page, which older versions of TikTok use.
+type sigiState struct {
+	UserModule struct {
+		Users map[string]tiktokUser      `json:"users"`
+		Stats map[string]tiktokUserStats `json:"stats"`
+	} `json:"UserModule"`
+}
+
+// parseProfileData finds the user and stats of uniqueID in the contents of the scripts __UNIVERSAL_DATA_FOR_REHYDRATION__ (universal) and SIGI_STATE (sigi), either of which may be empty. ok is false if neither has them.
+func parseProfileData(uniqueID, universal, sigi string) (user tiktokUser, stats tiktokUserStats, ok bool) {
+	if universal != "" {
+		var data universalData
+		if err := json.Unmarshal([]byte(universal), &data); err == nil {
+			info := data.DefaultScope.UserDetail.UserInfo
+			if info.User != nil && info.Stats != nil && strings.EqualFold(info.User.UniqueID, uniqueID) {
+				return *info.User, *info.Stats, true
+			}
+		} else if verbose {
+			log.Println("Failed to parse __UNIVERSAL_DATA_FOR_REHYDRATION__:", err)
+		}
+	}
+	if sigi != "" {
+		var state sigiState
+		if err := json.Unmarshal([]byte(sigi), &state); err == nil {
+			for id, u := range state.UserModule.Users {
+				if strings.EqualFold(id, uniqueID) {
+					return u, state.UserModule.Stats[id], true
+				}
+			}
+		} else if verbose {
+			log.Println("Failed to parse SIGI_STATE:", err)
+		}
+	}
+
+	return
+}
+
+// applyProfileData stores user and stats on ugc.
+func applyProfileData(ugc *ugcinfo.UGCInfo, user tiktokUser, stats tiktokUserStats) {
+	if user.Nickname != "" {
+		ugc.Name = user.Nickname
+	}
+	if user.Signature != "" {
+		ugc.Signature = user.Signature
+	}
+	ugc.FollowerCount = stats.FollowerCount
+	ugc.FollowingCount = stats.FollowingCount
+	ugc.HeartCount = stats.HeartCount
+	if ugc.HeartCount == 0 {
+		ugc.HeartCount = stats.Heart
+	}
+	ugc.VideoCount = stats.VideoCount
+	ugc.Verified = user.Verified
+	ugc.Private = user.PrivateAccount
+	ugc.Region = user.Region
+	ugc.Language = user.Language
+	ugc.BioLink = user.BioLink.Link
+	ugc.Avatar = user.AvatarLarger
+	if ugc.Avatar == "" {
+		ugc.Avatar = user.AvatarMedium
+	}
+}
+
+// readProfileData reads the profile data of ugc from the profile page opened in ctx.
+//
+// The state embedded in the page is preferred, and embedded tells whether it was found. If it is missing, what can be found in the DOM is used instead, which has no video count.
+func readProfileData(ctx context.Context, ugc *ugcinfo.UGCInfo) (embedded bool, err error) {
+	var universal, sigi string
+	if err := chromedp.Run(
+		ctx,
+		scriptText("__UNIVERSAL_DATA_FOR_REHYDRATION__", &universal),
+		scriptText("SIGI_STATE", &sigi),
+	); err != nil {
+		return false, err
+	}
+	if user, stats, ok := parseProfileData(ugc.UniqueID, universal, sigi); ok {
+		applyProfileData(ugc, user, stats)
+		return true, nil
+	}
+
+	if verbose {
+		log.Println("No embedded state found, reading profile data from the DOM")
+	}
+	var followers, following, likes, bioLink, avatar string
+	if err := chromedp.Run(
+		ctx,
+		firstText(selectors.FollowersCount, "", &followers),
+		firstText(selectors.FollowingCount, "", &following),
+		firstText(selectors.LikesCount, "", &likes),
+		firstText(selectors.BioLink, "", &bioLink),
+		firstText(selectors.Avatar, "src", &avatar),
+	); err != nil {
+		return false, err
+	}
+	if followers != "" { // keeps the follower count from the hashtag results if the page has none.
+		ugc.FollowerCount = parseCount(followers)
+	}
+	ugc.FollowingCount = parseCount(following)
+	ugc.HeartCount = parseCount(likes)
+	ugc.BioLink = bioLink
+	ugc.Avatar = avatar
+
+	return false, nil
+}
+
+// scriptText stores the text of the script whose id is id in text, or an empty string if there is no such script.
+func scriptText(id string, text *string) chromedp.Action {
+	return chromedp.Evaluate(`(() => { const s = document.getElementById(`+strconv.Quote(id)+`); return s ? s.textContent : ""; })()`, text)
+}
+
+// firstText tries XPaths in sels in order and stores the text (or the attribute attr if it is not empty) of the first node found in res. res is set to an empty string if none of them matches.
+func firstText(sels []string, attr string, res *string) chromedp.Action {
+	data, _ := json.Marshal(sels)
+	return chromedp.Evaluate(`(() => {
+		for (const sel of `+string(data)+`) {
+			const n = document.evaluate(sel, document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue;
+			if (n) return `+strconv.Quote(attr)+` ? (n.getAttribute(`+strconv.Quote(attr)+`) || "") : n.textContent.trim();
+		}
+		return "";
+	})()`, res)
+}
+
+// parseCount parses counts shown by TikTok like "987", "12.3K" and "1.2M". 0 is returned if s cannot be parsed.
+func parseCount(s string) int {
+	s = strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(s, ",", "")))
+	unit := 1.0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		unit = 1_000
+	case strings.HasSuffix(s, "M"):
+		unit = 1_000_000
+	case strings.HasSuffix(s, "B"):
+		unit = 1_000_000_000
+	}
+	if unit != 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+
+	return int(n*unit + 0.5)
+}
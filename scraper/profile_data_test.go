@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"os"
+	"testing"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+func TestParseProfileData(t *testing.T) {
+	universal, err := os.ReadFile("testdata/rehydration/universal.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigi, err := os.ReadFile("testdata/rehydration/sigi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, stats, ok := parseProfileData("fer.faceyoga", string(universal), string(sigi))
+	if !ok {
+		t.Fatal("profile data not found")
+	}
+	var ugc ugcinfo.UGCInfo
+	applyProfileData(&ugc, user, stats)
+	if ugc.FollowerCount != 123400 || ugc.FollowingCount != 321 || ugc.HeartCount != 4560000 || ugc.VideoCount != 210 {
+		t.Errorf("counts = %+v", ugc)
+	}
+	if !ugc.Verified || ugc.Private || ugc.Region != "ES" || ugc.Language != "es" || ugc.BioLink != "linktr.ee/fer.faceyoga" || ugc.Avatar == "" {
+		t.Errorf("profile = %+v", ugc)
+	}
+
+	user, stats, ok = parseProfileData("Fer.FaceYoga", "", string(sigi)) // falls back to SIGI_STATE.
+	if !ok {
+		t.Fatal("profile data not found in SIGI_STATE")
+	}
+	ugc = ugcinfo.UGCInfo{}
+	applyProfileData(&ugc, user, stats)
+	if !ugc.Private || ugc.FollowerCount != 99 || ugc.HeartCount != 500 || ugc.Avatar == "" {
+		t.Errorf("profile from SIGI_STATE = %+v", ugc)
+	}
+
+	if _, _, ok := parseProfileData("someone.else", string(universal), "not json"); ok {
+		t.Error("found profile data of someone else")
+	}
+}
+
+func TestParseCount(t *testing.T) {
+	for s, n := range map[string]int{
+		"987":    987,
+		"1,234":  1234,
+		"12.3K":  12300,
+		"1.2M":   1200000,
+		"3B":     3000000000,
+		" 45k ":  45000,
+		"":       0,
+		"abc":    0,
+		"0.1M":   100000,
+		"10.05K": 10050,
+	} {
+		if got := parseCount(s); got != n {
+			t.Errorf("parseCount(%q) = %d, want %d", s, got, n)
+		}
+	}
+}
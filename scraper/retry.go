@@ -0,0 +1,48 @@
+package scraper
+
+import (
+	"log"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// RetryPolicy tells calculateAPAndAI how to retry getting the statistics of a video, depending on why it failed.
+//
+// A busy API server is retried until BusyTimeout, while server errors, bad responses and connection errors are only retried up to Attempts times. Videos not found or with links the API server does not support are never retried, they are left out of AP and AI instead.
+type RetryPolicy struct {
+	Attempts        uint          // attempts at a video failing for other reasons than a busy API server
+	BusyTimeout     time.Duration // how long a video is retried in total, 0 means forever
+	InitialInterval time.Duration // wait before the first retry, which grows after every retry
+	MaxInterval     time.Duration // longest wait between two retries
+}
+
+// DefaultRetryPolicy is the RetryPolicy used unless SetRetryPolicy is called.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:        5,
+	BusyTimeout:     15 * time.Minute,
+	InitialInterval: 500 * time.Millisecond,
+	MaxInterval:     time.Minute,
+}
+
+var retryPolicy = DefaultRetryPolicy
+
+func SetRetryPolicy(p RetryPolicy) {
+	if p.Attempts == 0 {
+		p.Attempts = 1
+	}
+	retryPolicy = p
+	if verbose {
+		log.Printf("retryPolicy: %+v", retryPolicy)
+	}
+}
+
+// backOff returns the backoff of a video following p.
+func (p RetryPolicy) backOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = p.InitialInterval
+	b.MaxInterval = p.MaxInterval
+	b.MaxElapsedTime = p.BusyTimeout
+	b.Reset()
+	return b
+}
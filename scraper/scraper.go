@@ -20,17 +20,22 @@ import (
 	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
 	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
 	"github.com/jcbl1/tiktok_ugc_finder/utils"
-	"golang.org/x/sync/semaphore"
 )
 
 // Scrape scrapes UGC info according to a JSON file providing their unique IDs.
 //
-// It will supposingly save results anyway whether the process has finished successfully or not.
+// It will supposingly save results anyway whether the process has finished successfully or not, including when it is interrupted by SIGINT or SIGTERM.
 func Scrape(scrapedJSONFile string) error {
 	ugcs, err := ugcinfo.FromJSON(scrapedJSONFile) // gets UGCs from JSON.
 	if err != nil {
 		return err
 	}
+
+	return scrapeUGCs(scrapedJSONFile, ugcs)
+}
+
+// scrapeUGCs is the pipeline shared by Scrape and ScrapeSearch: it trims ugcs according to from, to and limit, scrapes them and saves the results. name is the name of the input, which the journal is named after.
+func scrapeUGCs(name string, ugcs []ugcinfo.UGCInfo) (err error) {
 	if from < 0 || from >= len(ugcs) { // sets from and to to a proper value.
 		from = len(ugcs)
 	}
@@ -41,51 +46,73 @@ func Scrape(scrapedJSONFile string) error {
 	if len(ugcs) > int(limit) { // respects the limit.
 		ugcs = ugcs[:limit]
 	}
-	log.Println("UGCs to be processed:", len(ugcs))
 
-	errs := make(chan error) // defines a channel to receive errors (if any) in closures.
-	// c := make(chan os.Signal, 1)
-	// signal.Notify(c, os.Interrupt)
-	// go func(ugcs *[]ugcinfo.UGCInfo, errChan chan error) {
-	// 	for sig := range c {
-	// 		log.Println(sig, "detected, saving results")
-	// 		if err := saveResults(*ugcs); err != nil {
-	// 			errChan <- err
-	// 		}
-	// 	}
-	// }(&ugcs, errs)
-
-	ctx, cancel := context.WithCancel(context.Background()) // defines the main context
-	defer cancel()
-	defer func(ugcs *[]ugcinfo.UGCInfo, errChan chan error) { // saves to file BEFORE ctx is canceled and this function is done.
-		if err := saveResults(*ugcs); err != nil {
-			errChan <- err
+	journal, todo, todoIndexes, err := prepareJournal(name, ugcs) // skips UGCs already done in a previous run if resume is set.
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+	log.Println("UGCs to be processed:", len(todo))
+
+	ctx, stop := notifyContext(context.Background()) // defines the main context, which is canceled on SIGINT/SIGTERM.
+	defer stop()
+	defer func(ugcs *[]ugcinfo.UGCInfo) { // saves to file BEFORE ctx is canceled and this function is done.
+		for i, index := range todoIndexes { // merges results of this run into those of previous runs.
+			(*ugcs)[index] = todo[i]
+		}
+		if serr := saveResults(dropOutOfFollowerRange(*ugcs)); serr != nil { // follower counts may have been unknown before scraping.
+			err = errors.Join(err, serr)
 		}
-	}(&ugcs, errs)
-	if err := scrapeProfileVideos(ctx, &ugcs); err != nil { // processes ugcs
+	}(&ugcs)
+	if err := scrapeProfileVideos(ctx, &todo, journal); err != nil { // processes ugcs
 		return err
 	}
 
 	return nil
 }
 
+// prepareJournal opens the journal of name.
+//
+// If resume is set, UGCs in ugcs that are already done according to the journal are replaced with their journaled results, and only the others are returned in todo, along with their indexes in ugcs. Otherwise all of ugcs are to do and the journal starts over.
+func prepareJournal(name string, ugcs []ugcinfo.UGCInfo) (journal *fileopers.Journal, todo []ugcinfo.UGCInfo, todoIndexes []int, err error) {
+	previous := make(map[string]ugcinfo.UGCInfo)
+	if resume {
+		if previous, err = fileopers.ReadJournal(name); err != nil {
+			return
+		}
+	}
+	for i, ugc := range ugcs {
+		if p, ok := previous[ugc.UniqueID]; ok && p.Status.Done() {
+			ugcs[i] = p
+			continue
+		}
+		todo = append(todo, ugc)
+		todoIndexes = append(todoIndexes, i)
+	}
+	if resume {
+		log.Println("Resuming,", len(ugcs)-len(todo), "UGCs already done")
+	}
+
+	journal, err = fileopers.OpenJournal(name, resume)
+	return
+}
+
 // TODO comments
-func ScrapeUnscraped(filename string) error {
+func ScrapeUnscraped(filename string) (err error) {
 	ugcs, err := ugcinfo.FromFile(filename)
 	if err != nil {
 		return err
 	}
 	log.Println("UGCs to be processed:", len(ugcs))
 
-	errs := make(chan error)                                // defines a channel to receive errors (if any) in closures.
-	ctx, cancel := context.WithCancel(context.Background()) // defines the main context
-	defer cancel()
-	defer func(ugcs *[]ugcinfo.UGCInfo, errChan chan error) { // saves to file BEFORE ctx is canceled and this function is done.
-		if err := saveResultsToExistingFile(ugcs, filename); err != nil {
-			errChan <- err
+	ctx, stop := notifyContext(context.Background()) // defines the main context, which is canceled on SIGINT/SIGTERM.
+	defer stop()
+	defer func(ugcs *[]ugcinfo.UGCInfo) { // saves to file BEFORE ctx is canceled and this function is done.
+		if serr := saveResultsToExistingFile(ugcs, filename); serr != nil {
+			err = errors.Join(err, serr)
 		}
-	}(&ugcs, errs)
-	if err := scrapeProfileVideos(ctx, &ugcs); err != nil { // processes ugcs
+	}(&ugcs)
+	if err := scrapeProfileVideos(ctx, &ugcs, nil); err != nil { // processes ugcs
 		return err
 	}
 
@@ -122,163 +149,132 @@ func saveResultsToExistingFile(ugcs *[]ugcinfo.UGCInfo, filename string) error {
 // scrapeProfileVideos does the real job for scraping.
 //
 // It allocates a browser and simulates the process of navigating, clicking and etc. ctxParent makes it easier to cancel the process when needed. ugcs is passed as a pointer so any changes will immediately take effect on the ugcs in Scrape(). An error is returned if it encounters any error that is due to the function itself (i.e. "Internal Error" is supposed to be returned).
-func scrapeProfileVideos(ctxParent context.Context, ugcs *[]ugcinfo.UGCInfo) error {
-	opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.Flag("headless", false), chromedp.DisableGPU) // customizes options used to allocate a browser.
-	var allocCtx context.Context
-	var cancel context.CancelFunc
-	if headless { // sets allocCtx to the default one if headless is true, or to the customized one if headless is false.
-		allocCtx, cancel = chromedp.NewExecAllocator(ctxParent, chromedp.DefaultExecAllocatorOptions[:]...)
-	} else {
-		allocCtx, cancel = chromedp.NewExecAllocator(ctxParent, opts...)
+//
+// UGCs are consumed by a pool of tabs (see SetTabs), each tab working on its own UGC at a time. Every finished UGC is recorded in journal, which may be nil.
+func scrapeProfileVideos(ctxParent context.Context, ugcs *[]ugcinfo.UGCInfo, journal *fileopers.Journal) error {
+	if len(*ugcs) == 0 {
+		return nil
 	}
+
+	allocCtx, cancel := newAllocator(ctxParent)
 	defer cancel()
 	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf)) // gets the browser context
 	defer cancel()
-
-	if err := chromedp.Run( // sets the viewport
-		ctx,
-		chromedp.EmulateViewport(1280, 720),
-	); err != nil {
+	if err := chromedp.Run(ctx); err != nil { // starts the browser so that tabs can be opened in it.
 		return err
 	}
-
-	if err := chromedp.Run( // navigates to the first user profile page.
-		ctx,
-		chromedp.Navigate(TIKTOK+"/@"+(*ugcs)[0].UniqueID),
-	); err != nil {
+	if err := prepareBrowser(ctx); err != nil { // e.g. imports cookies
 		return err
 	}
 
-	var refreshButtons []*cdp.Node // gets refresh button nodes
-	if err := chromedp.Run(
+	p := newPool(openTab, scrapeProfile)
+	return p.run(ctxParent, ctx, ugcs, journal)
+}
+
+// openTab opens a new tab in the browser of browserCtx, keeping its console for failure artifacts and letting statsProvider listen to it if it needs to. tab is the number of the tab, which is used to stagger the tabs a little.
+func openTab(browserCtx context.Context, tab int) (context.Context, context.CancelFunc, error) {
+	ctx, cancel := chromedp.NewContext(browserCtx)
+	if err := chromedp.Run(ctx); err != nil { // opens the tab so that its console can be listened to.
+		cancel()
+		return nil, nil, err
+	}
+	ctx = listenConsole(ctx)
+	if l, ok := statsProvider.(tabListener); ok {
+		l.listen(ctx)
+	}
+	if err := chromedp.Run( // sets the viewport and staggers the tabs a little.
 		ctx,
-		getRefreshButtons(&refreshButtons),
+		chromedp.ActionFunc(prepareTab),
+		chromedp.EmulateViewport(1280, 720),
+		chromedp.Sleep(time.Duration(tab)*utils.ShortInterval()),
 	); err != nil {
-		return err
+		cancel()
+		return nil, nil, err
 	}
 
-	if err := chromedp.Run( // clicks on the refresh button
+	return ctx, cancel, nil
+}
+
+// scrapeProfileOnce navigates to the profile page of ugc, reads its profile data and emails, and returns links of its recent videos, none if the profile data says it has no videos.
+//
+// first tells whether this is the first profile page of the tab, which needs the refresh button to be clicked. It is set to false once that is done. Emails are still stored for private accounts, while errProfilePrivate is returned.
+func scrapeProfileOnce(ctxTab context.Context, ugc *ugcinfo.UGCInfo, first *bool) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctxTab, profileTimeout) // a single profile should never hold the tab forever.
+	defer cancel()
+	ugc.Email = nil // the profile may be tried more than once.
+
+	if err := chromedp.Run( // navigates to the user profile page.
 		ctx,
-		chromedp.MouseClickNode(refreshButtons[0], chromedp.ButtonLeft),
+		chromedp.Navigate(TIKTOK+"/@"+ugc.UniqueID),
+		chromedp.WaitReady(`//body`),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
-	links, err := getProfileVideoLinks(ctx) // gets profile video links
+	state, err := checkProfileState(ctx, ugc.UniqueID) // tells deleted, private and captcha pages apart from normal ones.
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if state == errProfileNotFound || state == errCaptcha {
+		return nil, state
 	}
 
-	sem := semaphore.NewWeighted(5) // use semaphore to limit the amount of processes asking API server for help.
-
-	errs := make(chan error, len(*ugcs))   // error channel used to detect errors
-	finishes := make(chan int, len(*ugcs)) // channel used to check if all goroutines are done.
+	embedded, err := readProfileData(ctx, ugc) // gets current counts etc., which are nice to have but not worth failing for.
+	if err != nil {
+		log.Println("Failed to read profile data of", ugc.UniqueID+":", err)
+	}
 
-	go func(ctx context.Context, errChan chan error, finishChan chan int) { // gets AP and AI
-		if verbose {
-			log.Println("Getting AP and AI of the first user")
-		}
-		if lt, ap, ai, err := calculateAPAndAI(ctx, links); err != nil { // calculates AP and AI and if no error, stores them.
-			errChan <- err
-		} else {
-			(*ugcs)[0].AP = ap
-			(*ugcs)[0].AI = ai
-			(*ugcs)[0].LatestVideoTime = time.Unix(int64(lt), 0)
-		}
-		finishChan <- 0 // goroutine finished
-	}(ctx, errs, finishes)
-
-	// if verbose {
-	// 	log.Println("Getting AP and AI of the first user")
-	// }
-	// if lt, ap, ai, err := calculateAPAndAI(links); err != nil {
-	// 	return err
-	// } else {
-	// 	(*ugcs)[0].AP = ap
-	// 	(*ugcs)[0].AI = ai
-	// 	(*ugcs)[0].LatestVideoTime = time.Unix(int64(lt), 0)
-
-	// }
-
-	var mails []*mail.Address // gets emails
+	if verbose { // gets mails
+		log.Println("Getting emails")
+	}
+	var mails []*mail.Address
 	if err := findEmails(ctx, &mails); err != nil {
-		return err
+		return nil, err
 	}
 	for _, m := range mails {
-		(*ugcs)[0].Email = append((*ugcs)[0].Email, m.String())
+		ugc.Email = append(ugc.Email, m.String())
+	}
+	if state != nil {
+		return nil, state
+	}
+	if embedded && ugc.VideoCount == 0 { // neither the grid nor the refresh button would ever show up.
+		if verbose {
+			log.Println(ugc.UniqueID, "has no videos")
+		}
+		return nil, nil
 	}
 
-	// Sleep for an hour when testing
-	// chromedp.Run(
-	// 	ctx,
-	// 	chromedp.Sleep(time.Hour),
-	// )
-
-	for i := range (*ugcs)[1:] { // for each ugcs left, do the almost same thing as above.
+	if *first { // the first page of each tab usually needs the refresh button to be clicked.
+		var refreshButtons []*cdp.Node // gets refresh button nodes
 		if err := chromedp.Run(
 			ctx,
-			chromedp.Navigate(TIKTOK+"/@"+(*ugcs)[i+1].UniqueID),
+			getRefreshButtons(&refreshButtons),
 		); err != nil {
-			return err
+			return nil, err
 		}
 
-		links, err := getProfileVideoLinks(ctx)
-		if err != nil {
-			return err
-		}
-		go func(ctx context.Context, errChan chan error, finishChan chan int, index int) {
-			if err := sem.Acquire(context.TODO(), 1); err != nil { // acquires on semaphore
-				errChan <- err
-			}
-			// log.Printf("👻goroutine started[%d]", index)
-			log.Printf("Getting AP and AI of the %dth user\n", index+1)
-			if lt, ap, ai, err := calculateAPAndAI(ctx, links); err != nil {
-				errChan <- err
-			} else {
-				// log.Println("👻ap", ap, "ai", ai, "latestVideoTime", time.Unix(int64(lt), 0))
-				(*ugcs)[index].AP = ap
-				(*ugcs)[index].AI = ai
-				(*ugcs)[index].LatestVideoTime = time.Unix(int64(lt), 0)
+		if len(refreshButtons) != 0 { // clicks on the refresh button, unless videos are shown right away.
+			if err := chromedp.Run(
+				ctx,
+				chromedp.MouseClickNode(refreshButtons[0], chromedp.ButtonLeft),
+			); err != nil {
+				return nil, err
 			}
-			// log.Println("👻goroutine finished")
-			sem.Release(1) // releases to semaphore
-			finishChan <- index
-		}(ctx, errs, finishes, i+1)
-
-		if verbose { // gets mails
-			log.Println("Getting emails")
-		}
-		var mails []*mail.Address
-		if err := findEmails(ctx, &mails); err != nil {
-			return err
-		}
-		for _, m := range mails {
-			(*ugcs)[i+1].Email = append((*ugcs)[i+1].Email, m.String())
 		}
+		*first = false
 	}
 
-	finished := 0 // variable to count how many goroutines are finished.
-	for {
-		select {
-		case err := <-errs:
-			return err
-		case <-ctx.Done():
-			return errors.New("canceled")
-		case <-finishes: // finished increments by one and if it equals to the length of ugcs, this function stops waiting and returns nil
-			finished++
-			// log.Printf("⛳️finishes received[%d]. finished:%d", i, finished)
-			if finished == len(*ugcs) {
-				return nil
-			}
-		}
-	}
+	return getProfileVideoLinks(ctx) // gets profile video links
 }
 
 // getProfileVideoLinks gets video links that are not pinned on a profile page.
+//
+// errNoVideos is returned if the video grid does not show up, even after clicking the refresh button.
 func getProfileVideoLinks(ctx context.Context) ([]string, error) {
 	var anchors []*cdp.Node
 	var videoCount uint = 0
 	var links []string
+	refreshed := false
 	if verbose {
 		log.Println("Getting links")
 	}
@@ -288,9 +284,13 @@ func getProfileVideoLinks(ctx context.Context) ([]string, error) {
 
 		if err := chromedp.Run(
 			ctxTimeout,
-			chromedp.Nodes(`//*[@id="main-content-others_homepage"]/div/div[2]/div[*]/div/div[*]/div[1]/div/div/a[not(.//div[@data-e2e="video-card-badge"])]`, &anchors, chromedp.NodeReady),
+			waitFirstNodes(selectors.ProfileVideoAnchors, &anchors),
 			scrollToBottom(),
-		); err != nil && errors.Is(err, ctxTimeout.Err()) {
+		); err != nil && errors.Is(err, ctxTimeout.Err()) && ctx.Err() == nil {
+			if refreshed { // the grid never showed up, even after refreshing.
+				return nil, errNoVideos
+			}
+			refreshed = true
 			log.Println("😅 refresh")
 			// get refresh button node
 			var refreshButtons []*cdp.Node
@@ -301,12 +301,15 @@ func getProfileVideoLinks(ctx context.Context) ([]string, error) {
 				return nil, err
 			}
 			// click on the refresh button
-			if err := chromedp.Run(
-				ctx,
-				chromedp.MouseClickNode(refreshButtons[0], chromedp.ButtonLeft),
-			); err != nil {
-				return nil, err
+			if len(refreshButtons) != 0 {
+				if err := chromedp.Run(
+					ctx,
+					chromedp.MouseClickNode(refreshButtons[0], chromedp.ButtonLeft),
+				); err != nil {
+					return nil, err
+				}
 			}
+			continue // waits for the grid again.
 		} else if err != nil {
 			return nil, err
 		}
@@ -395,107 +398,135 @@ func debugLog(s string) chromedp.ActionFunc {
 	})
 }
 
+// closeLoginModal closes the login modal TikTok shows new visitors on the page opened in ctx, if there is one. errLoginWall is returned if it cannot be closed.
+func closeLoginModal(ctx context.Context) error {
+	log.Println("title stuff")
+	var titleNodes []*cdp.Node
+	if err := chromedp.Run(
+		ctx,
+		debugLog("waiting body ready"),
+		chromedp.WaitReady(`//body`),
+		debugLog("sleeping"),
+		chromedp.Sleep(utils.MediumInterval()),
+		debugLog("getting nodes"),
+		firstNodes(selectors.LoginModalTitle, &titleNodes),
+	); err != nil {
+		return err
+	}
+	log.Println("len(titleNodes):", len(titleNodes))
+	if len(titleNodes) != 0 {
+		if err := chromedp.Run(
+			ctx,
+			chromedp.KeyEvent(kb.Escape),
+			chromedp.Sleep(utils.ShortInterval()),
+			firstNodes(selectors.LoginModalTitle, &titleNodes),
+		); err != nil {
+			return err
+		}
+		if len(titleNodes) != 0 { // the modal cannot be closed.
+			return errLoginWall
+		}
+	}
+
+	return nil
+}
+
 // getRefreshButtons returns a task list to get refreshButtons.
+//
+// It waits until either the refresh button or the video grid shows up, refreshButtons is left empty in the latter case.
 func getRefreshButtons(refreshButtons *[]*cdp.Node) chromedp.Tasks {
 	return chromedp.Tasks{
 		// chromedp.WaitVisible(`//*[@id="login-modal-title"]`),
+		chromedp.ActionFunc(closeLoginModal),
+		// chromedp.KeyEvent(kb.Escape),
+		// chromedp.WaitNotPresent(`//*[@id="login-modal-title"]`),
+		chromedp.Sleep(utils.ShortInterval()),
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			log.Println("title stuff")
-			var titleNodes []*cdp.Node
-			if err := chromedp.Run(
-				ctx,
-				debugLog("waiting body ready"),
-				chromedp.WaitReady(`//body`),
-				debugLog("sleeping"),
-				chromedp.Sleep(utils.MediumInterval()),
-				debugLog("getting nodes"),
-				chromedp.Nodes(`//*[@id="login-modal-title"]`, &titleNodes, chromedp.AtLeast(0)),
-			); err != nil {
-				return err
-			}
-			log.Println("len(titleNodes):", len(titleNodes))
-			if len(titleNodes) != 0 {
+			var anchors []*cdp.Node
+			for {
 				if err := chromedp.Run(
 					ctx,
-					chromedp.KeyEvent(kb.Escape),
+					firstNodes(selectors.RefreshButton, refreshButtons),
+					firstNodes(selectors.ProfileVideoAnchors, &anchors),
 				); err != nil {
 					return err
 				}
+				if len(*refreshButtons) != 0 || len(anchors) != 0 {
+					return nil
+				}
+				if err := checkCaptcha(ctx); err != nil { // a captcha would make this wait forever.
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(200 * time.Millisecond):
+				}
 			}
-
-			return nil
 		}),
-		// chromedp.KeyEvent(kb.Escape),
-		// chromedp.WaitNotPresent(`//*[@id="login-modal-title"]`),
 		chromedp.Sleep(utils.ShortInterval()),
-		chromedp.WaitVisible(`//*[@id="main-content-others_homepage"]/div/div[2]/main/div/button`),
-		chromedp.Sleep(utils.ShortInterval()),
-		chromedp.Nodes(`//*[@id="main-content-others_homepage"]/div/div[2]/main/div/button`, refreshButtons),
 	}
 }
 
-// calculateAPAndAI trys to get video statistics from API server and will keep trying if it meets errors from other than ctx canceled.
-func calculateAPAndAI(ctx context.Context, links []string) (latestVideoTime int, ap int, ai float32, err error) {
-	var vss []utils.VideoStats
+// calculateAPAndAI trys to get video statistics of links from provider, retrying as retryPolicy says. videos are the statistics of links in the same order, so the first one is the latest video, except those not found or not supported by the API server, which are skipped.
+func calculateAPAndAI(ctx context.Context, provider VideoStatsProvider, links []string) (videos []ugcinfo.VideoStats, ap int, ai float32, err error) {
 	for i, link := range links {
 		if verbose {
 			log.Printf("Getting result of the %dth link: %s", i+1, link)
 		}
-		var vs utils.VideoStats
-		if i == 0 { // means the latest video
-			err = backoff.Retry(func() error {
-				select {
-				case <-ctx.Done():
-					return backoff.Permanent(errors.New("ctx canceled"))
-				default:
-					latestVideoTime, vs, err = utils.GetVideoStatsFromAPI(link)
-					if errors.Is(err, utils.ErrAPIBusy) && verbose {
-						log.Println("error:", err, "Retrying")
-					} else if err != nil {
-						log.Println("error:", err, "Retrying")
-					}
-					return err
-				}
-			}, backoff.NewExponentialBackOff())
-			if err != nil {
-				return
+		var vs ugcinfo.VideoStats
+		var failures uint // failures other than a busy API server
+		err = backoff.Retry(func() error {
+			if ctx.Err() != nil {
+				return backoff.Permanent(errors.New("ctx canceled"))
 			}
-		} else {
-			err = backoff.Retry(func() error {
-				select {
-				case <-ctx.Done():
-					return backoff.Permanent(errors.New("ctx canceled"))
-				default:
-					_, vs, err = utils.GetVideoStatsFromAPI(link)
-					if errors.Is(err, utils.ErrAPIBusy) && verbose {
-						log.Println("error:", err, "Retrying")
-					} else if err != nil {
-						log.Println("error:", err, "Retrying")
-					}
-					return err
+			vs, err = provider.VideoStats(ctx, link)
+			switch {
+			case err == nil:
+				return nil
+			case ctx.Err() != nil: // e.g. canceled while waiting for the rate limit.
+				return backoff.Permanent(err)
+			case errors.Is(err, utils.ErrVideoNotFound) || errors.Is(err, utils.ErrUnsupportedLink): // retrying would not help.
+				return backoff.Permanent(err)
+			case errors.Is(err, utils.ErrAPIBusy):
+				if verbose {
+					log.Println("error:", err, "Retrying")
 				}
-			}, backoff.NewExponentialBackOff())
-			if err != nil {
-				return
+				return err
 			}
+			failures++
+			if failures >= retryPolicy.Attempts {
+				return backoff.Permanent(err)
+			}
+			log.Println("error:", err, "Retrying")
+			return err
+		}, backoff.WithContext(retryPolicy.backOff(), ctx)) // stops waiting as soon as ctx is done.
+		if errors.Is(err, utils.ErrVideoNotFound) || errors.Is(err, utils.ErrUnsupportedLink) {
+			log.Println("Skipping video:", err)
+			err = nil
+			continue
 		}
-		vss = append(vss, vs)
+		if err != nil {
+			return
+		}
+		videos = append(videos, vs)
 	}
 
-	if len(vss) != 0 { // calculation
-		// log.Println("👻vss:",vss)
+	if len(videos) != 0 { // calculation
 		total := 0
 		ai_total := float32(0)
-		for _, vs := range vss {
-			// log.Println("👻vs:",vs)
+		played := 0 // videos without plays have no AI.
+		for _, vs := range videos {
 			total += vs.PlayCount
-			ai_total += float32(vs.DiggCount) / float32(vs.PlayCount)
-			// log.Println("👻total:",total,"ai_total:",ai_total)
+			if vs.PlayCount > 0 {
+				ai_total += float32(vs.DiggCount) / float32(vs.PlayCount)
+				played++
+			}
+		}
+		ap = total / len(videos)
+		if played != 0 {
+			ai = ai_total / float32(played)
 		}
-		ap = total / len(vss)
-		// log.Println("👻ap = total/len(vss), ap:",ap,"total:",total,"len(vss)",len(vss))
-		ai = ai_total / float32(len(vss))
-		// log.Println("👻ai = ai_total/float32(len(vss)), ai:",ai,"ai_total:",ai_total,"float32(len(vss))",float32(len(vss)))
 	}
 	return
 }
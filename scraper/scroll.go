@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// maxStaleScrolls is how many scrolls in a row may bring nothing new before a page is considered exhausted.
+const maxStaleScrolls = 3
+
+// scrollFor scrolls the page opened in ctx to the bottom until count reports at least want things loaded, or maxStaleScrolls scrolls in a row bring nothing new. Challenges showing up meanwhile are handled by getPastChallenges, what tells the human which page it is.
+func scrollFor(ctx context.Context, what string, want int, count func() (int, error)) error {
+	last := 0
+	for stale := 0; ; {
+		n, err := count()
+		if err != nil {
+			return err
+		}
+		if n > last {
+			last = n
+			stale = 0
+		} else {
+			stale++
+		}
+		if n >= want || stale >= maxStaleScrolls {
+			return nil
+		}
+
+		if err := checkCaptcha(ctx); errors.Is(err, errCaptcha) { // a captcha may show up while scrolling.
+			if err := getPastChallenges(ctx, what); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		if err := chromedp.Run(
+			ctx,
+			chromedp.Evaluate(`window.scrollTo(0, document.body.scrollHeight)`, nil),
+			chromedp.Sleep(utils.MediumInterval()),
+		); err != nil {
+			return err
+		}
+	}
+}
+
+// getPastChallenges closes the login modal and checks for a captcha on the page opened in ctx, pausing for a human to get past them in non-headless mode. what tells the human which page it is.
+//
+// In headless mode, challenges are returned as errors right away.
+func getPastChallenges(ctx context.Context, what string) error {
+	for attempt := 1; ; attempt++ {
+		err := closeLoginModal(ctx)
+		if err == nil {
+			err = checkCaptcha(ctx)
+		}
+		if !isChallenge(err) || headless || attempt > maxChallengeAttempts {
+			return err
+		}
+		if err := waitForHuman(ctx, what, err); err != nil {
+			return err
+		}
+	}
+}
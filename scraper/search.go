@@ -2,34 +2,228 @@ package scraper
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
-	"time"
+	"net/url"
+	"strings"
+	"unicode"
 
 	"github.com/chromedp/chromedp"
-	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
 )
 
-func Search(hashtags string) error {
-	opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.DisableGPU, chromedp.Flag("headless", false))
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
+// searchField is a field read from every item in a tab of search results. Sels are XPaths relative to the item, tried in order.
+type searchField struct {
+	Name string   `json:"name"`
+	Sels []string `json:"sels"`
+}
+
+// searchResult is a creator found in search results. followersKnown is false for creators found in the Videos tab, which shows no follower counts.
+type searchResult struct {
+	ugc            ugcinfo.UGCInfo
+	followersKnown bool
+}
+
+// ScrapeSearch searches TikTok for queries, then scrapes and saves the creators found just like Scrape does with the creators in a hashtag results file.
+func ScrapeSearch(queries []string) error {
+	ctx, stop := notifyContext(context.Background()) // searching can be canceled on SIGINT/SIGTERM too, there is nothing to save yet then.
+	ugcs, err := Search(ctx, queries)
+	stop()
+	if err != nil {
+		return err
+	}
+	log.Println("Creators found:", len(ugcs))
+
+	return scrapeUGCs(searchJournalName(queries), ugcs)
+}
+
+// Search searches TikTok for each of queries and returns the creators found in the Users and Videos tabs of the results, reading up to maxResults items from each tab.
+//
+// Creators are deduplicated and those whose follower counts are out of the range set in [ugcinfo] are dropped. Creators only found in the Videos tab are kept, as their follower counts are unknown until their profiles are scraped. ScrapeSearch drops them then if they are out of the range.
+func Search(ctxParent context.Context, queries []string) ([]ugcinfo.UGCInfo, error) {
+	allocCtx, cancel := newAllocator(ctxParent)
 	defer cancel()
 	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
 	defer cancel()
+	if err := chromedp.Run(ctx); err != nil {
+		return nil, err
+	}
+	if err := prepareBrowser(ctx); err != nil {
+		return nil, err
+	}
+	if err := prepareTab(ctx); err != nil {
+		return nil, err
+	}
+
+	var results []searchResult
+	for _, query := range queries {
+		users, err := searchTab(ctx, TIKTOK_SEARCH_USERS+url.QueryEscape(query), selectors.SearchUserItems, []searchField{
+			{Name: "unique_id", Sels: selectors.SearchUserUniqueID},
+			{Name: "nickname", Sels: selectors.SearchUserNickname},
+			{Name: "followers", Sels: selectors.SearchUserFollowers},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching users for %q: %w", query, err)
+		}
+		for _, user := range users {
+			followers, _, _ := strings.Cut(strings.TrimSpace(user["followers"]), " ") // e.g. "12.3K Followers"
+			results = append(results, searchResult{
+				ugc: ugcinfo.UGCInfo{
+					Name:          user["nickname"],
+					UniqueID:      uniqueIDFrom(user["unique_id"]),
+					FollowerCount: parseCount(followers),
+				},
+				followersKnown: followers != "",
+			})
+		}
+
+		videos, err := searchTab(ctx, TIKTOK_SEARCH_VIDEOS+url.QueryEscape(query), selectors.SearchVideoItems, []searchField{
+			{Name: "author", Sels: selectors.SearchVideoAuthor},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching videos for %q: %w", query, err)
+		}
+		for _, video := range videos {
+			results = append(results, searchResult{ugc: ugcinfo.UGCInfo{UniqueID: uniqueIDFrom(video["author"])}})
+		}
+		log.Println(len(users), "users and", len(videos), "videos found for", query)
+	}
+
+	return filterSearchResults(results), nil
+}
 
-	// Search by hashtags (actually by queries)
-	var screenshotOfSearchPage []byte
+// searchTab opens the tab of search results at u and scrolls it until maxResults items are found or no more show up. items are the XPaths of the items, tried in order. The fields of every item are returned as maps from field names to texts.
+func searchTab(ctx context.Context, u string, items []string, fields []searchField) ([]map[string]string, error) {
+	if verbose {
+		log.Println("Searching", u)
+	}
 	if err := chromedp.Run(
 		ctx,
-		chromedp.Navigate(TIKTOK_SEARCH+hashtags),
-		chromedp.WaitVisible(`//body`),
-		chromedp.Sleep(time.Second),
-		chromedp.FullScreenshot(&screenshotOfSearchPage, 100),
+		navigate(u),
+		chromedp.WaitReady(`//body`),
 	); err != nil {
-		return err
+		return nil, err
 	}
-	if err := fileopers.SaveScreenshot(screenshotOfSearchPage); err != nil {
-		return err
+	if err := getPastChallenges(ctx, u); err != nil {
+		return nil, err
 	}
 
-	return nil
+	var res []map[string]string
+	if err := scrollFor(ctx, u, int(maxResults), func() (int, error) {
+		var found []map[string]string
+		if err := chromedp.Run(ctx, readItems(items, fields, &found)); err != nil {
+			return 0, err
+		}
+		if len(found) > len(res) {
+			res = found
+		}
+		return len(res), nil
+	}); err != nil {
+		return nil, err
+	}
+	if len(res) > int(maxResults) {
+		res = res[:maxResults]
+	}
+
+	return res, nil
+}
+
+// readItems stores in res the fields of every node matched by the first matching XPath in items. A field not found in an item is an empty string.
+func readItems(items []string, fields []searchField, res *[]map[string]string) chromedp.Action {
+	itemsData, _ := json.Marshal(items)
+	fieldsData, _ := json.Marshal(fields)
+	return chromedp.Evaluate(`(() => {
+		const fields = `+string(fieldsData)+`;
+		for (const sel of `+string(itemsData)+`) {
+			const snapshot = document.evaluate(sel, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			if (snapshot.snapshotLength === 0) continue;
+			const res = [];
+			for (let i = 0; i < snapshot.snapshotLength; i++) {
+				const item = snapshot.snapshotItem(i), values = {};
+				for (const field of fields) {
+					values[field.name] = "";
+					for (const s of field.sels || []) {
+						const n = document.evaluate(s, item, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue;
+						if (n) {
+							values[field.name] = n.textContent.trim();
+							break;
+						}
+					}
+				}
+				res.push(values);
+			}
+			return res;
+		}
+		return [];
+	})()`, res)
+}
+
+// uniqueIDFrom returns the unique ID in s, which is either the unique ID itself, optionally prefixed with "@", or a link to the profile or a video of the creator.
+func uniqueIDFrom(s string) string {
+	s = strings.TrimSpace(s)
+	if _, after, ok := strings.Cut(s, "/@"); ok {
+		s, _, _ = strings.Cut(after, "/")
+		s, _, _ = strings.Cut(s, "?")
+		return s
+	}
+
+	return strings.TrimPrefix(s, "@")
+}
+
+// filterSearchResults deduplicates results by unique ID, preferring the ones with follower counts, and drops those whose follower counts are known to be out of range.
+func filterSearchResults(results []searchResult) []ugcinfo.UGCInfo {
+	var merged []searchResult
+	indexes := make(map[string]int) // indexes in merged by lowercased unique IDs
+	for _, r := range results {
+		if r.ugc.UniqueID == "" {
+			continue
+		}
+		id := strings.ToLower(r.ugc.UniqueID)
+		if i, ok := indexes[id]; !ok {
+			indexes[id] = len(merged)
+			merged = append(merged, r)
+		} else if !merged[i].followersKnown && r.followersKnown {
+			merged[i] = r
+		}
+	}
+
+	var ugcs []ugcinfo.UGCInfo
+	for _, r := range merged {
+		if r.followersKnown && !ugcinfo.InFollowerRange(r.ugc.FollowerCount) {
+			if verbose {
+				log.Println("Skipping", r.ugc.UniqueID, "with", r.ugc.FollowerCount, "followers")
+			}
+			continue
+		}
+		ugcs = append(ugcs, r.ugc)
+	}
+
+	return ugcs
+}
+
+// dropOutOfFollowerRange drops the UGCs in ugcs whose profiles were read and whose follower counts are out of the range set in [ugcinfo], like creators only found in the Videos tab of search results, whose follower counts are unknown until then. UGCs whose profiles were not read are kept, so that they can be tried again.
+func dropOutOfFollowerRange(ugcs []ugcinfo.UGCInfo) []ugcinfo.UGCInfo {
+	var kept []ugcinfo.UGCInfo
+	for _, ugc := range ugcs {
+		if (ugc.Status == ugcinfo.StatusOK || ugc.Status == ugcinfo.StatusPrivate) && !ugcinfo.InFollowerRange(ugc.FollowerCount) {
+			if verbose {
+				log.Println("Dropping", ugc.UniqueID, "with", ugc.FollowerCount, "followers")
+			}
+			continue
+		}
+		kept = append(kept, ugc)
+	}
+
+	return kept
+}
+
+// searchJournalName returns the name which the journal of a search for queries is named after.
+func searchJournalName(queries []string) string {
+	return "search-" + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.Join(queries, "_"))
 }
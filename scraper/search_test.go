@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"testing"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+func TestUniqueIDFrom(t *testing.T) {
+	for s, want := range map[string]string{
+		"alice":    "alice",
+		" @alice ": "alice",
+		"/@alice":  "alice",
+		"https://www.tiktok.com/@alice/video/123": "alice",
+		"https://www.tiktok.com/@alice?lang=en":   "alice",
+	} {
+		if got := uniqueIDFrom(s); got != want {
+			t.Errorf("uniqueIDFrom(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestFilterSearchResults(t *testing.T) {
+	if err := ugcinfo.SetMinMaxFollowerCount("1K", "1M"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ugcinfo.SetMinMaxFollowerCount("0", "INF") })
+
+	ugcs := filterSearchResults([]searchResult{
+		{ugc: ugcinfo.UGCInfo{UniqueID: "alice"}},                                                            // from the Videos tab first
+		{ugc: ugcinfo.UGCInfo{UniqueID: "Alice", Name: "Alice", FollowerCount: 12300}, followersKnown: true}, // then from the Users tab
+		{ugc: ugcinfo.UGCInfo{UniqueID: "bob", FollowerCount: 10}, followersKnown: true},
+		{ugc: ugcinfo.UGCInfo{UniqueID: "carol"}},
+		{ugc: ugcinfo.UGCInfo{UniqueID: "carol"}},
+		{ugc: ugcinfo.UGCInfo{}},
+	})
+	if len(ugcs) != 2 {
+		t.Fatalf("got %+v, want alice and carol", ugcs)
+	}
+	if ugcs[0].Name != "Alice" || ugcs[0].FollowerCount != 12300 {
+		t.Errorf("alice = %+v, want the one from the Users tab", ugcs[0])
+	}
+	if ugcs[1].UniqueID != "carol" {
+		t.Errorf("got %+v, want carol whose follower count is unknown", ugcs[1])
+	}
+}
+
+func TestDropOutOfFollowerRange(t *testing.T) {
+	if err := ugcinfo.SetMinMaxFollowerCount("1K", "1M"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ugcinfo.SetMinMaxFollowerCount("0", "INF") })
+
+	ugcs := dropOutOfFollowerRange([]ugcinfo.UGCInfo{
+		{UniqueID: "alice", FollowerCount: 12300, Status: ugcinfo.StatusOK},
+		{UniqueID: "bob", FollowerCount: 10, Status: ugcinfo.StatusOK}, // found in the Videos tab only
+		{UniqueID: "carol", FollowerCount: 5_000_000, Status: ugcinfo.StatusPrivate},
+		{UniqueID: "dave", Status: ugcinfo.StatusTimeout}, // not known yet
+	})
+	if len(ugcs) != 2 || ugcs[0].UniqueID != "alice" || ugcs[1].UniqueID != "dave" {
+		t.Errorf("got %+v, want alice and dave", ugcs)
+	}
+}
+
+func TestSearchJournalName(t *testing.T) {
+	if got, want := searchJournalName([]string{"face yoga", "skin/care"}), "search-face_yoga_skin_care"; got != want {
+		t.Errorf("searchJournalName() = %q, want %q", got, want)
+	}
+}
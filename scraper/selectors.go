@@ -0,0 +1,134 @@
+package scraper
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// SelectorPackVersion is the version of selector packs this package understands.
+const SelectorPackVersion = 1
+
+// defaultSelectorPack is the built-in selector pack.
+//
+//go:embed selectors.json
+var defaultSelectorPack []byte
+
+// SelectorPack holds the XPaths (and texts) used to find elements on TikTok pages, so that front-end changes of TikTok can be followed without a new release.
+//
+// Each element has a list of selectors which are tried in order, the first one matching anything wins.
+type SelectorPack struct {
+	Version             int      `json:"version"`
+	ProfileVideoAnchors []string `json:"profile_video_anchors"` // anchors of videos that are not pinned on a profile page
+	RefreshButton       []string `json:"refresh_button"`        // the button shown when a profile page fails to load videos
+	LoginModalTitle     []string `json:"login_modal_title"`     // the login modal which has to be closed
+	Captcha             []string `json:"captcha"`               // captcha containers
+	FollowersCount      []string `json:"followers_count"`       // follower count on a profile page, used when the page has no embedded state
+	FollowingCount      []string `json:"following_count"`       // following count on a profile page, ditto
+	LikesCount          []string `json:"likes_count"`           // likes (hearts) count on a profile page, ditto
+	BioLink             []string `json:"bio_link"`              // the link in the bio of a profile page, ditto
+	Avatar              []string `json:"avatar"`                // the avatar image on a profile page, ditto
+	NotFoundTexts       []string `json:"not_found_texts"`       // texts shown on pages of deleted accounts, case-insensitive, looked for if the page has no embedded state
+	PrivateTexts        []string `json:"private_texts"`         // texts shown on pages of private accounts, ditto
+	SearchUserItems     []string `json:"search_user_items"`     // creators in the Users tab of search results
+	SearchUserUniqueID  []string `json:"search_user_unique_id"` // the handle in a creator of the Users tab, relative to the creator
+	SearchUserNickname  []string `json:"search_user_nickname"`  // the nickname in a creator of the Users tab, ditto
+	SearchUserFollowers []string `json:"search_user_followers"` // the follower count in a creator of the Users tab, ditto
+	SearchVideoItems    []string `json:"search_video_items"`    // videos in the Videos tab of search results
+	SearchVideoAuthor   []string `json:"search_video_author"`   // the handle of the author in a video of the Videos tab, relative to the video
+}
+
+// selectors is the selector pack in use.
+var selectors SelectorPack
+
+func init() {
+	if err := json.Unmarshal(defaultSelectorPack, &selectors); err != nil {
+		panic(err)
+	}
+}
+
+// LoadSelectors overrides the built-in selector pack with the JSON file named filename. Elements missing in the file keep their built-in selectors.
+func LoadSelectors(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	pack := selectors
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return err
+	}
+	if err := pack.validate(); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	selectors = pack
+	if verbose {
+		log.Println("selectors loaded from", filename)
+	}
+
+	return nil
+}
+
+// validate checks if p can be used.
+func (p SelectorPack) validate() error {
+	if p.Version != SelectorPackVersion {
+		return fmt.Errorf("unsupported selector pack version %d, want %d", p.Version, SelectorPackVersion)
+	}
+	for name, sels := range map[string][]string{
+		"profile_video_anchors": p.ProfileVideoAnchors,
+		"refresh_button":        p.RefreshButton,
+		"login_modal_title":     p.LoginModalTitle,
+		"captcha":               p.Captcha,
+		"search_user_items":     p.SearchUserItems,
+		"search_user_unique_id": p.SearchUserUniqueID,
+		"search_video_items":    p.SearchVideoItems,
+		"search_video_author":   p.SearchVideoAuthor,
+	} {
+		if len(sels) == 0 {
+			return errors.New("no selectors for " + name)
+		}
+	}
+
+	return nil
+}
+
+// firstNodes tries sels in order and stores in nodes what the first matching one matches. nodes is left empty if none of them matches.
+func firstNodes(sels []string, nodes *[]*cdp.Node) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		for _, sel := range sels {
+			if err := chromedp.Nodes(sel, nodes, chromedp.AtLeast(0)).Do(ctx); err != nil {
+				return err
+			}
+			if len(*nodes) != 0 {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// waitFirstNodes is like firstNodes but keeps trying until any of sels matches or ctx is done.
+func waitFirstNodes(sels []string, nodes *[]*cdp.Node) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		for {
+			if err := firstNodes(sels, nodes).Do(ctx); err != nil {
+				return err
+			}
+			if len(*nodes) != 0 {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(200 * time.Millisecond):
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultSelectors(t *testing.T) {
+	if err := selectors.validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadSelectors(t *testing.T) {
+	defaults := selectors
+	defer func() { selectors = defaults }()
+
+	dir := t.TempDir()
+	partial := filepath.Join(dir, "partial.json")
+	os.WriteFile(partial, []byte(`{"version": 1, "refresh_button": ["//button[@id=\"refresh\"]", "//button"]}`), 0644)
+	if err := LoadSelectors(partial); err != nil {
+		t.Fatal(err)
+	}
+	if len(selectors.RefreshButton) != 2 || selectors.RefreshButton[0] != `//button[@id="refresh"]` {
+		t.Errorf("RefreshButton = %v, want the overridden one", selectors.RefreshButton)
+	}
+	if len(selectors.ProfileVideoAnchors) != len(defaults.ProfileVideoAnchors) {
+		t.Errorf("ProfileVideoAnchors = %v, want the built-in one", selectors.ProfileVideoAnchors)
+	}
+
+	wrongVersion := filepath.Join(dir, "wrong_version.json")
+	os.WriteFile(wrongVersion, []byte(`{"version": 99}`), 0644)
+	if err := LoadSelectors(wrongVersion); err == nil {
+		t.Error("loaded a selector pack of an unsupported version")
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	os.WriteFile(empty, []byte(`{"version": 1, "captcha": []}`), 0644)
+	if err := LoadSelectors(empty); err == nil {
+		t.Error("loaded a selector pack without captcha selectors")
+	}
+}
@@ -0,0 +1,45 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownGrace is how long in-flight API requests are waited for after the main context is canceled.
+const shutdownGrace = 30 * time.Second
+
+// notifyContext returns a copy of parent that is canceled on the first SIGINT or SIGTERM, so that partial results can be saved. A second signal quits the process right away.
+//
+// stop must be called once the context is no longer needed.
+func notifyContext(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-sigs:
+			log.Println(sig, "detected, saving results. Send it again to quit immediately")
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case sig := <-sigs:
+			log.Println(sig, "detected again, quitting without saving")
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigs)
+		close(done)
+		cancel()
+	}
+}
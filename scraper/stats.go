@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// VideoStatsProvider provides the statistics of videos, which AP and AI are calculated from.
+type VideoStatsProvider interface {
+	// VideoStats returns the create time and the statistics of the video at link.
+	VideoStats(ctx context.Context, link string) (ugcinfo.VideoStats, error)
+}
+
+// tabListener is implemented by VideoStatsProviders which read what the tabs scraping profiles download. listen is called on every tab opened, before its first navigation, and forget once the profile of uniqueID is done with, so that what was kept for it can be dropped.
+type tabListener interface {
+	listen(ctx context.Context)
+	forget(uniqueID string)
+}
+
+// forgetProfile tells statsProvider that the profile of uniqueID is done with.
+func forgetProfile(uniqueID string) {
+	if l, ok := statsProvider.(tabListener); ok {
+		l.forget(uniqueID)
+	}
+}
+
+// statsProvider provides the statistics of the videos of the UGCs scraped.
+var statsProvider VideoStatsProvider = apiProvider{}
+
+// SetStatsProvider sets the VideoStatsProvider used to calculate AP and AI.
+func SetStatsProvider(p VideoStatsProvider) {
+	statsProvider = p
+}
+
+// SetStatsBackend sets the VideoStatsProvider used to calculate AP and AI by name: "api" asks the API server, "browser" reads the item lists profile pages download and asks the API server for the videos not found there, and "fake" makes up statistics without any requests, e.g. for trying the scraper out.
+func SetStatsBackend(name string) error {
+	switch name {
+	case "api":
+		SetStatsProvider(apiProvider{})
+	case "browser":
+		SetStatsProvider(newBrowserProvider(apiProvider{}))
+	case "fake":
+		SetStatsProvider(fakeProvider{})
+	default:
+		return errors.New("unknown stats backend: " + name)
+	}
+	if verbose {
+		log.Println("statsBackend:", name)
+	}
+
+	return nil
+}
+
+// statsCache keeps the statistics got from the API server across runs. It may be nil, in which case nothing is cached.
+var statsCache *fileopers.VideoStatsCache
+
+// SetStatsCache sets the cache of the statistics got from the API server.
+func SetStatsCache(c *fileopers.VideoStatsCache) {
+	statsCache = c
+}
+
+// apiProvider provides video statistics from the API servers set in [utils], respecting its rate limits and flow control. Videos in statsCache are not asked for again.
+type apiProvider struct{}
+
+func (apiProvider) VideoStats(ctx context.Context, link string) (ugcinfo.VideoStats, error) {
+	if vs, ok := statsCache.Get(utils.VideoID(link)); ok {
+		if verbose {
+			log.Println("Cached:", link)
+		}
+		vs.URL = link
+		return vs, nil
+	}
+	var res utils.APIResult
+	if err := utils.DoAPI(ctx, func(ctx context.Context, c *utils.APIClient) (err error) {
+		res, err = c.Video(ctx, link)
+		return err
+	}); err != nil {
+		return ugcinfo.VideoStats{}, err
+	}
+	vs := videoStatsFrom(link, res)
+	if err := statsCache.Put(vs); err != nil { // not worth failing for.
+		log.Println("Failed to cache", link+":", err)
+	}
+
+	return vs, nil
+}
+
+// videoStatsFrom returns the statistics of the video at link found by the API server.
+func videoStatsFrom(link string, res utils.APIResult) ugcinfo.VideoStats {
+	return ugcinfo.VideoStats{
+		URL:          link,
+		ID:           utils.VideoID(link),
+		CreateTime:   time.Unix(int64(res.CreateTime), 0),
+		PlayCount:    res.Statistics.PlayCount,
+		DiggCount:    res.Statistics.DiggCount,
+		CommentCount: res.Statistics.CommentCount,
+		ShareCount:   res.Statistics.ShareCount,
+		CollectCount: res.Statistics.CollectCount,
+		Duration:     res.Duration,
+		Description:  res.Desc,
+		Hashtags:     res.Hashtags,
+		MusicTitle:   res.Music.Title,
+		MusicAuthor:  res.Music.Author,
+	}
+}
+
+// fakeProvider makes up statistics of videos from their links with [utils.MockVideo], the same ones for the same link every time, and the same ones the mock API server answers with.
+type fakeProvider struct{}
+
+func (fakeProvider) VideoStats(ctx context.Context, link string) (ugcinfo.VideoStats, error) {
+	if err := ctx.Err(); err != nil {
+		return ugcinfo.VideoStats{}, err
+	}
+	return videoStatsFrom(link, utils.MockVideo(utils.VideoID(link))), nil
+}
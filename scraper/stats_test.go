@@ -0,0 +1,176 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fileopers "github.com/jcbl1/tiktok_ugc_finder/file_opers"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+	"github.com/jcbl1/tiktok_ugc_finder/utils"
+)
+
+// statsFunc is a VideoStatsProvider made of a function.
+type statsFunc func(ctx context.Context, link string) (ugcinfo.VideoStats, error)
+
+func (f statsFunc) VideoStats(ctx context.Context, link string) (ugcinfo.VideoStats, error) {
+	return f(ctx, link)
+}
+
+// useRetryPolicy sets p as retryPolicy for the test.
+func useRetryPolicy(t *testing.T, p RetryPolicy) {
+	t.Helper()
+	old := retryPolicy
+	t.Cleanup(func() { retryPolicy = old })
+	retryPolicy = p
+}
+
+func TestCalculateAPAndAI(t *testing.T) {
+	useRetryPolicy(t, RetryPolicy{Attempts: 3, BusyTimeout: time.Second, InitialInterval: time.Millisecond, MaxInterval: 10 * time.Millisecond})
+	plays := map[string]int{"a": 1000, "b": 3000}
+	calls := 0
+	provider := statsFunc(func(ctx context.Context, link string) (ugcinfo.VideoStats, error) {
+		calls++
+		if calls == 1 { // fails once, then succeeds on retry.
+			return ugcinfo.VideoStats{}, errors.New("api busy")
+		}
+		return ugcinfo.VideoStats{URL: link, PlayCount: plays[link], DiggCount: plays[link] / 10}, nil
+	})
+
+	videos, ap, ai, err := calculateAPAndAI(context.Background(), provider, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(videos) != 2 || videos[0].URL != "a" || videos[1].URL != "b" || ap != 2000 || ai < 0.099 || ai > 0.101 {
+		t.Errorf("got videos %+v, AP %d and AI %f, want AP 2000 and AI 0.1", videos, ap, ai)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, _, err := calculateAPAndAI(ctx, provider, []string{"a"}); err == nil {
+		t.Error("no error with a canceled context")
+	}
+}
+
+func TestCalculateAPAndAIRetries(t *testing.T) {
+	useRetryPolicy(t, RetryPolicy{Attempts: 3, BusyTimeout: 200 * time.Millisecond, InitialInterval: time.Millisecond, MaxInterval: 10 * time.Millisecond})
+	calls := make(map[string]int)
+	provider := statsFunc(func(ctx context.Context, link string) (ugcinfo.VideoStats, error) {
+		calls[link]++
+		switch link {
+		case "deleted":
+			return ugcinfo.VideoStats{}, &utils.APIError{Link: link, StatusCode: 404, Err: utils.ErrVideoNotFound}
+		case "broken":
+			return ugcinfo.VideoStats{}, &utils.APIError{Link: link, StatusCode: 500, Err: utils.ErrAPIServer}
+		case "busy":
+			return ugcinfo.VideoStats{}, &utils.APIError{Link: link, StatusCode: 503, Err: utils.ErrAPIBusy}
+		}
+		return ugcinfo.VideoStats{URL: link, PlayCount: 100}, nil
+	})
+
+	videos, ap, _, err := calculateAPAndAI(context.Background(), provider, []string{"a", "deleted", "b"})
+	if err != nil || len(videos) != 2 || ap != 100 || calls["deleted"] != 1 {
+		t.Errorf("got videos %+v, AP %d and error %v after %d calls for the deleted video, want it skipped without retrying", videos, ap, err, calls["deleted"])
+	}
+	if _, _, _, err := calculateAPAndAI(context.Background(), provider, []string{"broken"}); !errors.Is(err, utils.ErrAPIServer) || calls["broken"] != 3 {
+		t.Errorf("got %v after %d calls, want the server error after 3", err, calls["broken"])
+	}
+	if _, _, _, err := calculateAPAndAI(context.Background(), provider, []string{"busy"}); !errors.Is(err, utils.ErrAPIBusy) || calls["busy"] <= 3 {
+		t.Errorf("got %v after %d calls, want busy retried until the busy timeout", err, calls["busy"])
+	}
+}
+
+func TestCalculateAPAndAICanceled(t *testing.T) {
+	useRetryPolicy(t, RetryPolicy{Attempts: 3, InitialInterval: time.Minute, MaxInterval: time.Minute})
+	provider := statsFunc(func(ctx context.Context, link string) (ugcinfo.VideoStats, error) {
+		return ugcinfo.VideoStats{}, &utils.APIError{Link: link, StatusCode: 503, Err: utils.ErrAPIBusy}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond) // canceled while waiting to retry.
+	defer cancel()
+
+	start := time.Now()
+	if _, _, _, err := calculateAPAndAI(ctx, provider, []string{"busy"}); err == nil {
+		t.Error("no error after being canceled")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("returned %s after being canceled, want right away", d)
+	}
+}
+
+func TestFakeProvider(t *testing.T) {
+	link := "https://www.tiktok.com/@fer.faceyoga/video/7310293679493614853"
+	a, err := fakeProvider{}.VideoStats(context.Background(), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := fakeProvider{}.VideoStats(context.Background(), link)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("got %+v and %+v for the same link", a, b)
+	}
+	if a.ID != "7310293679493614853" || a.CreateTime.Year() != 2023 || a.PlayCount < 1000 || a.DiggCount == 0 || a.Duration == 0 || len(a.Hashtags) == 0 {
+		t.Errorf("VideoStats(%q) = %+v", link, a)
+	}
+}
+
+func TestSetStatsBackend(t *testing.T) {
+	old := statsProvider
+	t.Cleanup(func() { statsProvider = old })
+	for _, name := range []string{"api", "browser", "fake"} {
+		if err := SetStatsBackend(name); err != nil {
+			t.Errorf("SetStatsBackend(%q): %v", name, err)
+		}
+	}
+	if _, ok := statsProvider.(fakeProvider); !ok {
+		t.Errorf("statsProvider = %T, want fakeProvider", statsProvider)
+	}
+	if err := SetStatsBackend("tiktok"); err == nil {
+		t.Error("no error for an unknown backend")
+	}
+}
+
+func TestAPIProviderCache(t *testing.T) {
+	api := fakeAPIServer(t)
+	var requests atomic.Int32
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		api.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(counting.Close)
+	u, err := url.Parse(counting.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.SetAPIServer(u)
+	cache, err := fileopers.OpenVideoStatsCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	t.Cleanup(func() { SetStatsCache(nil) })
+	SetStatsCache(cache)
+
+	first, err := apiProvider{}.VideoStats(context.Background(), "https://www.tiktok.com/@alice/video/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := "https://www.tiktok.com/@alice/video/7?lang=en" // the same video
+	second, err := apiProvider{}.VideoStats(context.Background(), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("the API server was asked %d times, want once", n)
+	}
+	if first.CollectCount != 14 || first.Duration != 17 || !reflect.DeepEqual(first.Hashtags, []string{"ugc"}) || first.MusicTitle != "original sound" {
+		t.Errorf("got %+v from the API server", first)
+	}
+	if second.URL != link || second.PlayCount != first.PlayCount || !second.CreateTime.Equal(first.CreateTime) || second.CollectCount != first.CollectCount || !reflect.DeepEqual(second.Hashtags, first.Hashtags) {
+		t.Errorf("got %+v from the cache, want %+v at %s", second, first, link)
+	}
+}
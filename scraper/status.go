@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+// profileTimeout is the longest time a single profile page is allowed to take.
+const profileTimeout = 2 * time.Minute
+
+// Errors describing why a single UGC failed. They are mapped to a ugcinfo.Status by statusOf.
+var (
+	errProfileNotFound = errors.New("account not found")
+	errProfilePrivate  = errors.New("account is private")
+	errCaptcha         = errors.New("captcha shown")
+	errAPI             = errors.New("api error")
+	errNoVideos        = errors.New("no videos found")
+)
+
+// checkProfileState checks the profile page of uniqueID currently opened in ctx.
+//
+// It returns errProfileNotFound, errProfilePrivate or errCaptcha as the state if the page is not a normal profile page, and nil otherwise. err is only non-nil when the checking itself failed. The state embedded in the page is trusted over NotFoundTexts and PrivateTexts, which a bio or a caption may contain too, so the texts are only looked for when it is missing.
+func checkProfileState(ctx context.Context, uniqueID string) (state error, err error) {
+	var captchaNodes []*cdp.Node
+	var universal, sigi, bodyText string
+	if err := chromedp.Run(
+		ctx,
+		firstNodes(selectors.Captcha, &captchaNodes),
+		scriptText("__UNIVERSAL_DATA_FOR_REHYDRATION__", &universal),
+		scriptText("SIGI_STATE", &sigi),
+		chromedp.Text(`//body`, &bodyText, chromedp.NodeReady),
+	); err != nil {
+		return nil, err
+	}
+	if len(captchaNodes) != 0 {
+		return errCaptcha, nil
+	}
+	if user, _, ok := parseProfileData(uniqueID, universal, sigi); ok {
+		if user.PrivateAccount {
+			return errProfilePrivate, nil
+		}
+		return nil, nil
+	}
+
+	bodyText = strings.ToLower(bodyText)
+	for _, t := range selectors.NotFoundTexts {
+		if strings.Contains(bodyText, strings.ToLower(t)) {
+			return errProfileNotFound, nil
+		}
+	}
+	for _, t := range selectors.PrivateTexts {
+		if strings.Contains(bodyText, strings.ToLower(t)) {
+			return errProfilePrivate, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// statusOf maps err to the ugcinfo.Status it stands for.
+func statusOf(err error) ugcinfo.Status {
+	switch {
+	case err == nil:
+		return ugcinfo.StatusOK
+	case errors.Is(err, errProfileNotFound):
+		return ugcinfo.StatusNotFound
+	case errors.Is(err, errProfilePrivate):
+		return ugcinfo.StatusPrivate
+	case errors.Is(err, errCaptcha), errors.Is(err, errLoginWall):
+		return ugcinfo.StatusCaptcha
+	case errors.Is(err, errAPI):
+		return ugcinfo.StatusAPIError
+	case errors.Is(err, context.DeadlineExceeded):
+		return ugcinfo.StatusTimeout
+	default:
+		return ugcinfo.StatusError
+	}
+}
+
+// markFailed records err on ugc so that the run can go on with other UGCs.
+func markFailed(ugc *ugcinfo.UGCInfo, err error) {
+	ugc.Status = statusOf(err)
+	ugc.ErrorMessage = err.Error()
+	log.Printf("⚠️ %s: %s (%s)", ugc.UniqueID, ugc.ErrorMessage, ugc.Status)
+}
@@ -0,0 +1,31 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	ugcinfo "github.com/jcbl1/tiktok_ugc_finder/ugc_info"
+)
+
+func TestStatusOf(t *testing.T) {
+	cases := []struct {
+		err    error
+		status ugcinfo.Status
+	}{
+		{nil, ugcinfo.StatusOK},
+		{errProfileNotFound, ugcinfo.StatusNotFound},
+		{errProfilePrivate, ugcinfo.StatusPrivate},
+		{errCaptcha, ugcinfo.StatusCaptcha},
+		{errLoginWall, ugcinfo.StatusCaptcha},
+		{fmt.Errorf("%w: %w", errAPI, errors.New("api busy")), ugcinfo.StatusAPIError},
+		{fmt.Errorf("waiting: %w", context.DeadlineExceeded), ugcinfo.StatusTimeout},
+		{errors.New("something else"), ugcinfo.StatusError},
+	}
+	for _, c := range cases {
+		if got := statusOf(c.err); got != c.status {
+			t.Errorf("statusOf(%v) = %s, want %s", c.err, got, c.status)
+		}
+	}
+}
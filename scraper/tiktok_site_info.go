@@ -1,6 +1,27 @@
 package scraper
 
-const (
-	TIKTOK        = "https://www.tiktok.com"
-	TIKTOK_SEARCH = TIKTOK + "/search?q="
+import (
+	"log"
+	"strings"
+)
+
+// URLs of TikTok. They can be changed with SetTikTokURL, e.g. to point to a local fake site.
+var (
+	TIKTOK               = "https://www.tiktok.com"
+	TIKTOK_SEARCH        = TIKTOK + "/search?q="
+	TIKTOK_SEARCH_USERS  = TIKTOK + "/search/user?q="
+	TIKTOK_SEARCH_VIDEOS = TIKTOK + "/search/video?q="
+	TIKTOK_TAG           = TIKTOK + "/tag/"
 )
+
+// SetTikTokURL sets the base URL of TikTok, which all the other URLs are built on.
+func SetTikTokURL(u string) {
+	TIKTOK = strings.TrimSuffix(u, "/")
+	TIKTOK_SEARCH = TIKTOK + "/search?q="
+	TIKTOK_SEARCH_USERS = TIKTOK + "/search/user?q="
+	TIKTOK_SEARCH_VIDEOS = TIKTOK + "/search/video?q="
+	TIKTOK_TAG = TIKTOK + "/tag/"
+	if verbose {
+		log.Println("TIKTOK:", TIKTOK)
+	}
+}
@@ -12,7 +12,14 @@ var (
 	limit           uint
 	headless        bool
 	// minFollowerCount, maxFollowerCount int
-	from, to int
+	from, to     int
+	tabs         uint
+	resume       bool
+	userDataDir  string
+	cookiesFile  string
+	browserWSURL string
+	maxResults   uint
+	postsPerTag  uint
 )
 
 // func SetVars(rvn uint,rf string,v bool,l uint,h bool,m,M string,fr,t int){
@@ -66,3 +73,52 @@ func SetFromTo(f, t int) {
 		log.Println("from", from, "to", to)
 	}
 }
+
+func SetTabs(t uint) {
+	tabs = t
+	if verbose {
+		log.Println("tabs:", tabs)
+	}
+}
+
+func SetResume(r bool) {
+	resume = r
+	if verbose {
+		log.Println("resume:", resume)
+	}
+}
+
+func SetUserDataDir(d string) {
+	userDataDir = d
+	if verbose {
+		log.Println("userDataDir:", userDataDir)
+	}
+}
+
+func SetCookiesFile(f string) {
+	cookiesFile = f
+	if verbose {
+		log.Println("cookiesFile:", cookiesFile)
+	}
+}
+
+func SetBrowserWSURL(u string) {
+	browserWSURL = u
+	if verbose {
+		log.Println("browserWSURL:", browserWSURL)
+	}
+}
+
+func SetMaxResults(m uint) {
+	maxResults = m
+	if verbose {
+		log.Println("maxResults:", maxResults)
+	}
+}
+
+func SetPostsPerTag(p uint) {
+	postsPerTag = p
+	if verbose {
+		log.Println("postsPerTag:", postsPerTag)
+	}
+}
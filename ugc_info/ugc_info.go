@@ -12,23 +12,74 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-// type VideoStats struct {
-// 	DiggCount int `json:"digg_count"`
-// 	PlayCount int `json:"play_count"`
-// }
+// VideoStats is a video of a UGC sampled to calculate AP and AI.
+type VideoStats struct {
+	URL          string    `json:"url"`
+	ID           string    `json:"id"`
+	CreateTime   time.Time `json:"create_time"`
+	PlayCount    int       `json:"play_count"`
+	DiggCount    int       `json:"digg_count"` // likes
+	CommentCount int       `json:"comment_count"`
+	ShareCount   int       `json:"share_count"`
+	CollectCount int       `json:"collect_count"` // favorites
+	Duration     int       `json:"duration"`      // in seconds
+	Description  string    `json:"description"`
+	Hashtags     []string  `json:"hashtags"` // without "#"
+	MusicTitle   string    `json:"music_title"`
+	MusicAuthor  string    `json:"music_author"`
+}
 
 // UGCInfo is a structure for cared infomation about a UGC.
 type UGCInfo struct {
-	Name            string    `json:"name"`
-	Signature       string    `json:"signature"`
-	UniqueID        string    `json:"unique_id"`
-	FollowerCount   int       `json:"follower_count"`
-	Gender          string    `json:"gender"`
-	AP              int       `json:"ap"`
-	AI              float32   `json:"ai"`
-	Email           []string  `json:"email"`
-	LatestVideoTime time.Time `json:"latest_video_time"`
-	// VideosStats     []VideoStats
+	Name            string       `json:"name"`
+	Signature       string       `json:"signature"`
+	UniqueID        string       `json:"unique_id"`
+	FollowerCount   int          `json:"follower_count"`
+	Gender          string       `json:"gender"`
+	AP              int          `json:"ap"`
+	AI              float32      `json:"ai"`
+	Email           []string     `json:"email"`
+	LatestVideoTime time.Time    `json:"latest_video_time"`
+	Status          Status       `json:"status"`
+	ErrorMessage    string       `json:"error_message"`
+	FollowingCount  int          `json:"following_count"`
+	HeartCount      int          `json:"heart_count"`
+	VideoCount      int          `json:"video_count"`
+	Verified        bool         `json:"verified"`
+	Private         bool         `json:"private"`
+	Region          string       `json:"region"`
+	Language        string       `json:"language"`
+	BioLink         string       `json:"bio_link"`
+	Avatar          string       `json:"avatar"`
+	VideosStats     []VideoStats `json:"videos_stats"` // videos sampled, the latest first
+}
+
+// Status tells how scraping a UGC went.
+type Status string
+
+// Possible values of Status. An empty Status means the UGC has not been scraped yet.
+const (
+	StatusOK       Status = "ok"
+	StatusNotFound Status = "not_found"
+	StatusPrivate  Status = "private"
+	StatusTimeout  Status = "timeout"
+	StatusCaptcha  Status = "captcha"
+	StatusAPIError Status = "api_error"
+	StatusError    Status = "error" // any other failure
+)
+
+// Done reports whether s is final, i.e. scraping the UGC again would not make a difference.
+func (s Status) Done() bool {
+	return s == StatusOK || s == StatusNotFound || s == StatusPrivate
+}
+
+// NeedsMending reports whether row, a row below the headers of the main sheet of a results XLSX file, is of a UGC to be scraped again, i.e. one whose status is empty or not done. Rows of files saved before the Status column was added have no status, so those with AP 0 are to be scraped again instead.
+func NeedsMending(row []string) bool {
+	if len(row) > 9 { // has the Status column, J.
+		return !Status(row[9]).Done()
+	}
+
+	return len(row) > 5 && row[5] == "0"
 }
 
 // func (u UGCInfo) String() string {
@@ -97,7 +148,7 @@ func FromJSON(scrapedJSONFile string) ([]UGCInfo, error) {
 	// Redundancy declusion
 	present := make(map[string]bool)
 	for _, hashRes := range hashRess {
-		if _, ok := present[hashRes.Author.UniqueID]; !ok && hashRes.AuthorStats.FollowerCount >= minFollowerCount && hashRes.AuthorStats.FollowerCount <= maxFollowerCount {
+		if _, ok := present[hashRes.Author.UniqueID]; !ok && InFollowerRange(hashRes.AuthorStats.FollowerCount) {
 			present[hashRes.Author.UniqueID] = true
 			ugcs = append(ugcs, UGCInfo{
 				Name:          hashRes.Author.Nickname,
@@ -145,8 +196,8 @@ func fromExcel(f *os.File, ugcs *[]UGCInfo) error {
 	if err != nil {
 		return err
 	}
-	for _, row := range sheet {
-		if row[5] == "0" {
+	for i, row := range sheet {
+		if i != 0 && NeedsMending(row) { // skips the headers.
 			fc, _ := strconv.Atoi(row[3])
 			*ugcs = append(*ugcs, UGCInfo{
 				Name:          row[0],
@@ -2,29 +2,32 @@ package ugcinfo
 
 import (
 	"encoding/json"
-	"errors"
 	"testing"
 
 	"github.com/google/uuid"
 )
 
 func TestFromJSON(t *testing.T) {
-	if ugcs, err := FromJSON("/home/jcbl1/tiktok_hashtag_data/faceyoga/posts_fewer.json"); err != nil {
-		t.Error(err)
-	} else {
-		if len(ugcs) != 2 {
-			t.Error(errors.New("len not 2"))
-		}
-		jsonOutput(t, ugcs)
+	if err := SetMinMaxFollowerCount("1K", "1M"); err != nil {
+		t.Fatal(err)
 	}
+	t.Cleanup(func() { SetMinMaxFollowerCount("0", "INF") })
 
-	uuid_ := uuid.NewString()
-	if _, err := FromJSON(uuid_); err != nil {
-		t.Logf("Error when input wrong filename: %s", err)
+	ugcs, err := FromJSON("testdata/posts.json") // alice twice, bob with too few followers and carol.
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonOutput(t, ugcs)
+	if len(ugcs) != 2 || ugcs[0].UniqueID != "alice" || ugcs[1].UniqueID != "carol" {
+		t.Fatalf("got %+v, want alice and carol", ugcs)
+	}
+	if a := ugcs[0]; a.Name != "Alice" || a.Signature != "UGC creator" || a.FollowerCount != 12300 {
+		t.Errorf("alice = %+v", a)
 	}
 
-	//test decoder
-	t.Fail()
+	if _, err := FromJSON(uuid.NewString()); err == nil {
+		t.Error("no error for a file that does not exist")
+	}
 }
 
 func jsonOutput(t *testing.T, s interface{}) {
@@ -34,3 +37,21 @@ func jsonOutput(t *testing.T, s interface{}) {
 	}
 	t.Logf("%s\n", buf)
 }
+
+func TestNeedsMending(t *testing.T) {
+	for _, c := range []struct {
+		row  []string
+		want bool
+	}{
+		{[]string{"Alice", "", "alice", "1000", "", "0", "0", "", "", "api_error"}, true},
+		{[]string{"Bob", "", "bob", "1000", "", "0", "0", "", "", "private"}, false},
+		{[]string{"Carol", "", "carol", "1000", "", "300", "0.1", "", "", "ok"}, false},
+		{[]string{"Dave", "", "dave", "1000", "", "0", "0", "", "", ""}, true},
+		{[]string{"Erin", "", "erin", "1000", "", "0"}, true}, // saved before the Status column was added
+		{[]string{"Frank", "", "frank", "1000", "", "300"}, false},
+	} {
+		if got := NeedsMending(c.row); got != c.want {
+			t.Errorf("NeedsMending(%q) = %t, want %t", c.row, got, c.want)
+		}
+	}
+}
@@ -57,3 +57,8 @@ func SetMinMaxFollowerCount(m, M string) error {
 
 	return nil
 }
+
+// InFollowerRange reports whether followerCount is between the minimum and maximum follower counts set by SetMinMaxFollowerCount.
+func InFollowerRange(followerCount int) bool {
+	return followerCount >= minFollowerCount && followerCount <= maxFollowerCount
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// Defaults of the flow control of requests to the API server.
+const (
+	DefaultAPIMaxConcurrency   = 8
+	DefaultAPISlowLatency      = 10 * time.Second
+	DefaultAPIBreakerThreshold = 0.5
+	DefaultAPIBreakerCooldown  = 30 * time.Second
+)
+
+// Flow control of requests to the API server, see DoAPI.
+var (
+	apiConcurrency = NewAIMD("API", 5, DefaultAPIMaxConcurrency)
+	apiBreaker     = NewCircuitBreaker("API", DefaultAPIBreakerThreshold, DefaultAPIBreakerCooldown)
+	apiSlowLatency = DefaultAPISlowLatency
+)
+
+// SetAPIFlowControl sets how requests to the API server are let through by DoAPI: up to maxConcurrency at the same time, with requests slower than slowLatency taken as a sign of overload, and a circuit breaker opening for breakerCooldown once breakerThreshold (0 to 1, 0 disables it) of the latest requests failed.
+func SetAPIFlowControl(maxConcurrency uint, slowLatency time.Duration, breakerThreshold float64, breakerCooldown time.Duration) {
+	apiConcurrency = NewAIMD("API", min(5, maxConcurrency), maxConcurrency)
+	apiSlowLatency = slowLatency
+	apiBreaker = NewCircuitBreaker("API", breakerThreshold, breakerCooldown)
+	if verbose {
+		log.Printf("API flow control: up to %d requests at the same time, slow after %s, circuit breaker at %.0f%% failures for %s", maxConcurrency, slowLatency, breakerThreshold*100, breakerCooldown)
+	}
+}
+
+// DoAPI runs request, which sends a request to the API server with c, once the circuit breaker, the concurrency limit and the rate limits let it, and lets them learn from its outcome. c is for the API server picked by the balance set by SetAPIServers.
+//
+// Busy responses, timeouts and responses slower than the slow latency halve the concurrency limit, while other responses slowly raise it. All failures but videos not found and unsupported links count towards opening the circuit breaker, and all of them but busy responses too towards ejecting the API server. Requests canceled by ctx count for nothing.
+func DoAPI(ctx context.Context, request func(ctx context.Context, c *APIClient) error) error {
+	probe, err := apiBreaker.Allow(ctx)
+	if err != nil {
+		return err
+	}
+	if err := apiConcurrency.Acquire(ctx); err != nil {
+		apiBreaker.Abandon(probe)
+		return err
+	}
+	if err := WaitAPI(ctx); err != nil {
+		apiConcurrency.Abandon()
+		apiBreaker.Abandon(probe)
+		return err
+	}
+
+	servers := apiServers
+	server := servers.pick()
+	start := time.Now()
+	err = request(ctx, &APIClient{Server: server.url, Timeout: apiTimeout})
+	if ctx.Err() != nil {
+		servers.abandon(server)
+		apiConcurrency.Abandon()
+		apiBreaker.Abandon(probe)
+		return err
+	}
+	failed := err != nil && !errors.Is(err, ErrVideoNotFound) && !errors.Is(err, ErrUnsupportedLink)
+	servers.release(server, failed && !errors.Is(err, ErrAPIBusy))
+	apiConcurrency.Release(errors.Is(err, ErrAPIBusy) || errors.Is(err, context.DeadlineExceeded) || time.Since(start) > apiSlowLatency)
+	apiBreaker.Done(probe, failed)
+
+	return err
+}
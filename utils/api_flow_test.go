@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDoAPI(t *testing.T) {
+	oldConcurrency, oldBreaker, oldSlow := apiConcurrency, apiBreaker, apiSlowLatency
+	t.Cleanup(func() { apiConcurrency, apiBreaker, apiSlowLatency = oldConcurrency, oldBreaker, oldSlow })
+	SetAPIFlowControl(8, time.Second, 0.5, time.Hour)
+
+	busy := &APIError{StatusCode: 503, Err: ErrAPIBusy}
+	notFound := &APIError{StatusCode: 404, Err: ErrVideoNotFound}
+	respond := func(err error) error {
+		return DoAPI(context.Background(), func(ctx context.Context, c *APIClient) error { return err })
+	}
+	for i := 0; i < breakerWindow; i++ {
+		if err := respond(notFound); !errors.Is(err, ErrVideoNotFound) {
+			t.Fatalf("got %v, want the error of the request", err)
+		}
+	}
+	limit := apiConcurrency.Limit()
+	if apiBreaker.State() != "closed" || limit < 5 {
+		t.Fatalf("videos not found changed the breaker to %s or the concurrency to %d", apiBreaker.State(), limit)
+	}
+
+	respond(busy)
+	if l := apiConcurrency.Limit(); l != limit/2 {
+		t.Errorf("concurrency = %d after a busy response, want %d", l, limit/2)
+	}
+	for i := 0; i < breakerWindow && apiBreaker.State() == "closed"; i++ {
+		respond(busy)
+	}
+	if s := apiBreaker.State(); s != "open" {
+		t.Errorf("breaker = %s after busy responses, want open", s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	called := false
+	if err := DoAPI(ctx, func(ctx context.Context, c *APIClient) error { called = true; return nil }); err == nil || called {
+		t.Errorf("got %v and called %v while the breaker is open, want the request held back", err, called)
+	}
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Ways requests are balanced between API servers.
+const (
+	BalanceRoundRobin = "round-robin" // each server in turn
+	BalanceLeastBusy  = "least-busy"  // the server with the fewest requests in flight
+)
+
+// Defaults of how API servers are checked.
+const (
+	DefaultAPIEjectAfter     = 3
+	DefaultAPIHealthInterval = 30 * time.Second
+)
+
+// apiHealthTimeout is the longest time a health check of an API server may take.
+const apiHealthTimeout = 10 * time.Second
+
+// apiServer is one of the API servers requests are balanced between.
+type apiServer struct {
+	url      url.URL
+	inFlight int
+	failures int // in a row, by requests and health checks
+	ejected  bool
+}
+
+// apiServerPool balances requests between API servers, ejecting those which failed ejectAfter times in a row until they pass a health check.
+type apiServerPool struct {
+	mu         sync.Mutex
+	servers    []*apiServer
+	balance    string
+	ejectAfter uint // 0 never ejects
+	next       int  // index of the server to start looking from
+}
+
+// apiServers are the API servers set by SetAPIServers.
+var apiServers = &apiServerPool{balance: BalanceRoundRobin, ejectAfter: DefaultAPIEjectAfter}
+
+// SetAPIServers sets the API servers requests are balanced between with balance, either BalanceRoundRobin or BalanceLeastBusy. A server is ejected after ejectAfter failures in a row, 0 meaning never, and re-added once it passes a health check, see StartAPIHealthChecks.
+func SetAPIServers(servers []*url.URL, balance string, ejectAfter uint) error {
+	if len(servers) == 0 {
+		return errors.New("no API server")
+	}
+	if balance != BalanceRoundRobin && balance != BalanceLeastBusy {
+		return errors.New("unknown balance: " + balance)
+	}
+	p := &apiServerPool{balance: balance, ejectAfter: ejectAfter}
+	for _, a := range servers {
+		s := &apiServer{url: *a}
+		if s.url.Scheme == "" {
+			s.url.Scheme = "http"
+		}
+		p.servers = append(p.servers, s)
+	}
+	apiServers = p
+	if verbose && len(servers) > 1 {
+		log.Println(len(servers), "API servers balanced", balance)
+	}
+
+	return nil
+}
+
+// SetAPIServer sets a as the only API server.
+func SetAPIServer(a *url.URL) {
+	SetAPIServers([]*url.URL{a}, apiServers.balance, apiServers.ejectAfter)
+}
+
+// pick returns the server the next request goes to, counting the request in flight until it is released. Ejected servers are only picked if all of them are, so that requests keep going to something.
+func (p *apiServerPool) pick() *apiServer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.servers) == 0 {
+		return &apiServer{}
+	}
+	allEjected := true
+	for _, s := range p.servers {
+		if !s.ejected {
+			allEjected = false
+			break
+		}
+	}
+
+	var picked *apiServer
+	pickedAt := 0
+	for i := range p.servers {
+		j := (p.next + i) % len(p.servers)
+		s := p.servers[j]
+		if s.ejected && !allEjected {
+			continue
+		}
+		if picked == nil || p.balance == BalanceLeastBusy && s.inFlight < picked.inFlight {
+			picked, pickedAt = s, j
+		}
+		if p.balance == BalanceRoundRobin {
+			break
+		}
+	}
+	p.next = (pickedAt + 1) % len(p.servers)
+	picked.inFlight++
+
+	return picked
+}
+
+// release tells p that a request picked by pick is done, and whether s failed it.
+func (p *apiServerPool) release(s *apiServer, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.inFlight--
+	p.record(s, failed, "requests")
+}
+
+// abandon tells p that a request picked by pick ended without an outcome.
+func (p *apiServerPool) abandon(s *apiServer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.inFlight--
+}
+
+// record records whether s failed a request or a health check, ejecting or re-adding it. what tells which in logs. p.mu must be held.
+func (p *apiServerPool) record(s *apiServer, failed bool, what string) {
+	if !failed {
+		s.failures = 0
+		if s.ejected {
+			s.ejected = false
+			log.Println("API server", s.url.String(), "re-added")
+		}
+		return
+	}
+	s.failures++
+	if !s.ejected && p.ejectAfter > 0 && s.failures >= int(p.ejectAfter) {
+		s.ejected = true
+		log.Println("API server", s.url.String(), "ejected after", s.failures, "failed", what, "in a row")
+	}
+}
+
+// first returns the URL of the first server which is not ejected, or of the first one if all of them are.
+func (p *apiServerPool) first() url.URL {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.servers) == 0 {
+		return url.URL{}
+	}
+	for _, s := range p.servers {
+		if !s.ejected {
+			return s.url
+		}
+	}
+	return p.servers[0].url
+}
+
+// StartAPIHealthChecks checks every interval whether the API servers set by SetAPIServers answer, until ctx is done. Failed checks count towards ejecting a server like failed requests, and an ejected server passing a check is re-added. Health checks are not subject to the rate limits.
+func StartAPIHealthChecks(ctx context.Context, interval time.Duration) {
+	p := apiServers
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			p.check(ctx)
+		}
+	}()
+}
+
+// check checks all the servers of p at the same time.
+func (p *apiServerPool) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, s := range p.servers {
+		wg.Add(1)
+		go func(s *apiServer) {
+			defer wg.Done()
+			err := checkAPIServer(ctx, s.url)
+			if ctx.Err() != nil {
+				return
+			}
+			if verbose && err != nil {
+				log.Println("API server", s.url.String(), "health check failed:", err)
+			}
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.record(s, err != nil, "health checks")
+		}(s)
+	}
+	wg.Wait()
+}
+
+// checkAPIServer tells whether the API server at u answers without a server error.
+func checkAPIServer(ctx context.Context, u url.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, apiHealthTimeout)
+	defer cancel()
+	u.Path = "/"
+	u.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errors.New(resp.Status)
+	}
+
+	return nil
+}
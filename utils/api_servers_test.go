@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAPIServerPool(t *testing.T) {
+	var servers []*url.URL
+	for _, s := range []string{"a:8000", "b:8000", "c:8000"} {
+		servers = append(servers, &url.URL{Host: s})
+	}
+	oldServers := apiServers
+	t.Cleanup(func() { apiServers = oldServers })
+	if err := SetAPIServers(servers, "random", 3); err == nil {
+		t.Error("no error for an unknown balance")
+	}
+
+	SetAPIServers(servers, BalanceRoundRobin, 3)
+	p := apiServers
+	var picked []string
+	for i := 0; i < 4; i++ {
+		s := p.pick()
+		picked = append(picked, s.url.Host)
+		p.release(s, false)
+	}
+	if got := picked[0] + picked[1] + picked[2] + picked[3]; got != "a:8000b:8000c:8000a:8000" || p.servers[0].url.Scheme != "http" {
+		t.Errorf("round-robin picked %v", picked)
+	}
+
+	SetAPIServers(servers, BalanceLeastBusy, 3)
+	p = apiServers
+	p.pick()
+	b := p.pick()
+	p.pick()
+	p.release(b, false)
+	if s := p.pick(); s != b {
+		t.Errorf("least-busy picked %s with %s idle", s.url.Host, b.url.Host)
+	}
+
+	c := p.servers[2]
+	for i := 0; i < 3; i++ {
+		p.inFlight(c)
+		p.release(c, true)
+	}
+	if !c.ejected {
+		t.Fatal("not ejected after 3 failures in a row")
+	}
+	for i := 0; i < 10; i++ {
+		if s := p.pick(); s == c {
+			t.Fatal("picked an ejected server")
+		}
+	}
+	for _, s := range p.servers[:2] {
+		s.ejected = true
+	}
+	if s := p.pick(); s.url.Host == "" {
+		t.Fatal("picked nothing with all the servers ejected")
+	}
+	p.mu.Lock()
+	p.record(c, false, "health checks")
+	p.mu.Unlock()
+	if c.ejected || c.failures != 0 {
+		t.Error("not re-added after a success")
+	}
+}
+
+// inFlight counts a request to s in flight, as if s was picked.
+func (p *apiServerPool) inFlight(s *apiServer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.inFlight++
+}
+
+func TestAPIFailover(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var badRequests atomic.Int32
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api" {
+			badRequests.Add(1)
+		}
+		if down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"create_time": 1700000000, "statistics": {"play_count": 100}}`))
+	}))
+	defer bad.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"create_time": 1700000000, "statistics": {"play_count": 100}}`))
+	}))
+	defer good.Close()
+
+	oldServers, oldConcurrency, oldBreaker := apiServers, apiConcurrency, apiBreaker
+	t.Cleanup(func() { apiServers, apiConcurrency, apiBreaker = oldServers, oldConcurrency, oldBreaker })
+	SetAPIFlowControl(1, time.Second, 0, 0)
+	badURL, _ := url.Parse(bad.URL)
+	goodURL, _ := url.Parse(good.URL)
+	SetAPIServers([]*url.URL{badURL, goodURL}, BalanceRoundRobin, 2)
+
+	video := func(ctx context.Context, c *APIClient) error {
+		_, err := c.Video(ctx, "https://www.tiktok.com/@a/video/1")
+		return err
+	}
+	for i := 0; i < 10; i++ {
+		DoAPI(context.Background(), video)
+	}
+	if n := badRequests.Load(); n != 2 {
+		t.Fatalf("%d requests to the failing server, want 2 before ejecting it", n)
+	}
+
+	down.Store(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	StartAPIHealthChecks(ctx, 10*time.Millisecond)
+	for deadline := time.Now().Add(5 * time.Second); apiServers.first() != *badURL; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("not re-added after recovering")
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := DoAPI(context.Background(), video); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := badRequests.Load(); n != 3 {
+		t.Errorf("%d requests to the recovered server, want 3", n)
+	}
+}
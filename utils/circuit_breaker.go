@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// breakerState is the state of a CircuitBreaker.
+type breakerState string
+
+// Possible values of breakerState.
+const (
+	breakerClosed   breakerState = "closed"    // requests go through
+	breakerOpen     breakerState = "open"      // requests wait for the cooldown
+	breakerHalfOpen breakerState = "half-open" // a single probe goes through, the others wait for its outcome
+)
+
+// breakerWindow is the number of latest outcomes the failure rate of a CircuitBreaker is calculated from.
+const breakerWindow = 20
+
+// CircuitBreaker pauses all requests to something once too many of the latest ones failed. After a cooldown, a single probe is let through: the breaker closes if it succeeds and opens again for another cooldown if it fails. A nil *CircuitBreaker lets everything through.
+type CircuitBreaker struct {
+	name      string // shown in logs
+	threshold float64
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	outcomes []bool // latest outcomes, true for failures
+	openedAt time.Time
+	changed  chan struct{} // closed whenever state changes
+	now      func() time.Time
+}
+
+// NewCircuitBreaker returns a CircuitBreaker which opens for cooldown when at least threshold (0 to 1) of the latest requests failed. nil is returned if threshold is 0.
+func NewCircuitBreaker(name string, threshold float64, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     breakerClosed,
+		changed:   make(chan struct{}),
+		now:       time.Now,
+	}
+}
+
+// Allow blocks until a request is allowed, or ctx is done. probe tells whether the request is the probe of a half-open breaker. Every successful Allow has to be followed by a Done with the outcome of the request, or an Abandon.
+func (b *CircuitBreaker) Allow(ctx context.Context) (probe bool, err error) {
+	if b == nil {
+		return false, ctx.Err()
+	}
+	for {
+		b.mu.Lock()
+		var wait <-chan time.Time
+		switch b.state {
+		case breakerClosed:
+			b.mu.Unlock()
+			return false, nil
+		case breakerOpen:
+			if left := b.cooldown - b.now().Sub(b.openedAt); left > 0 {
+				wait = time.After(left)
+				break
+			}
+			b.setState(breakerHalfOpen)
+			b.mu.Unlock()
+			return true, nil
+		}
+		changed := b.changed // half-open, waits for the probe.
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-changed:
+		case <-wait:
+		}
+	}
+}
+
+// Abandon tells b that a request allowed by Allow ended without an outcome, e.g. because it was canceled by the caller. Another request is let through as the probe if it was the probe.
+func (b *CircuitBreaker) Abandon(probe bool) {
+	if b == nil || !probe {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedAt = b.now().Add(-b.cooldown)
+	b.state = breakerOpen
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Done records the outcome of a request allowed by Allow.
+func (b *CircuitBreaker) Done(probe, failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		if failed {
+			b.open("the probe failed")
+		} else {
+			b.outcomes = b.outcomes[:0]
+			b.setState(breakerClosed)
+		}
+		return
+	}
+	if b.state != breakerClosed { // a late outcome of a request allowed before the breaker opened.
+		return
+	}
+
+	b.outcomes = append(b.outcomes, failed)
+	if len(b.outcomes) > breakerWindow {
+		b.outcomes = b.outcomes[len(b.outcomes)-breakerWindow:]
+	}
+	failures := 0
+	for _, f := range b.outcomes {
+		if f {
+			failures++
+		}
+	}
+	if len(b.outcomes) >= breakerWindow/2 && float64(failures)/float64(len(b.outcomes)) >= b.threshold {
+		b.open(fmt.Sprintf("%d of the latest %d requests failed", failures, len(b.outcomes)))
+	}
+}
+
+// open opens b because of why.
+func (b *CircuitBreaker) open(why string) {
+	b.openedAt = b.now()
+	b.setState(breakerOpen)
+	log.Println(b.name, "circuit breaker open,", why+", pausing for", b.cooldown)
+}
+
+// setState sets the state of b and wakes up the requests waiting for it.
+func (b *CircuitBreaker) setState(s breakerState) {
+	if verbose && s != breakerOpen && s != b.state {
+		log.Println(b.name, "circuit breaker", s)
+	}
+	b.state = s
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// State returns the state of b as a string: "closed", "open" or "half-open".
+func (b *CircuitBreaker) State() string {
+	if b == nil {
+		return string(breakerClosed)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.state)
+}
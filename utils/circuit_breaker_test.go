@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	if NewCircuitBreaker("test", 0, time.Second) != nil {
+		t.Error("a breaker with a threshold of 0 is not nil")
+	}
+	var nilBreaker *CircuitBreaker
+	if probe, err := nilBreaker.Allow(context.Background()); probe || err != nil {
+		t.Errorf("nil breaker: %v, %v", probe, err)
+	}
+
+	now := time.Now()
+	b := NewCircuitBreaker("test", 0.5, time.Minute)
+	b.now = func() time.Time { return now }
+	request := func(failed bool) {
+		t.Helper()
+		probe, err := b.Allow(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Done(probe, failed)
+	}
+	for i := 0; i < breakerWindow; i++ {
+		request(i%3 == 0) // a third fails
+	}
+	if s := b.State(); s != "closed" {
+		t.Fatalf("state = %s with a third failing, want closed", s)
+	}
+	for i := 0; i < breakerWindow/2 && b.State() == "closed"; i++ {
+		request(true)
+	}
+	if s := b.State(); s != "open" {
+		t.Fatalf("state = %s with most failing, want open", s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := b.Allow(ctx); err == nil {
+		t.Fatal("allowed while open")
+	}
+
+	now = now.Add(time.Minute) // the cooldown is over.
+	probe, err := b.Allow(context.Background())
+	if err != nil || !probe || b.State() != "half-open" {
+		t.Fatalf("got %v, %v in state %s, want a probe in half-open", probe, err, b.State())
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := b.Allow(ctx); err == nil {
+		t.Fatal("allowed another request while probing")
+	}
+	b.Done(true, true)
+	if s := b.State(); s != "open" {
+		t.Fatalf("state = %s after the probe failed, want open", s)
+	}
+
+	now = now.Add(time.Minute)
+	probe, _ = b.Allow(context.Background())
+	b.Abandon(probe)
+	waiting := make(chan bool)
+	go func() {
+		probe, _ := b.Allow(context.Background())
+		waiting <- probe
+	}()
+	if probe := <-waiting; !probe {
+		t.Fatal("no new probe after abandoning one")
+	}
+	b.Done(true, false)
+	if s := b.State(); s != "closed" {
+		t.Fatalf("state = %s after the probe succeeded, want closed", s)
+	}
+	request(true) // the failures before opening are forgotten.
+	if s := b.State(); s != "closed" {
+		t.Errorf("state = %s after a single failure, want closed", s)
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// AIMD limits how many things run at the same time with additive increase, multiplicative decrease: the limit grows by one after about as many successes in a row as the limit, and is halved on every overload.
+type AIMD struct {
+	name     string // shown in logs
+	mu       sync.Mutex
+	limit    float64
+	max      float64
+	inFlight int
+	changed  chan struct{} // closed whenever a slot may have been freed
+}
+
+// NewAIMD returns an AIMD starting at initial and growing up to maxLimit things at the same time. It never goes below 1.
+func NewAIMD(name string, initial, maxLimit uint) *AIMD {
+	maxLimit = max(maxLimit, 1)
+	return &AIMD{
+		name:    name,
+		limit:   float64(min(max(initial, 1), maxLimit)),
+		max:     float64(maxLimit),
+		changed: make(chan struct{}),
+	}
+}
+
+// Acquire blocks until one more thing is allowed to run, or ctx is done. Every successful Acquire has to be followed by a Release or an Abandon.
+func (a *AIMD) Acquire(ctx context.Context) error {
+	for {
+		a.mu.Lock()
+		if a.inFlight < int(a.limit) {
+			a.inFlight++
+			a.mu.Unlock()
+			return nil
+		}
+		changed := a.changed
+		a.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Release tells a that a thing is done, and whether it found what it runs against overloaded.
+func (a *AIMD) Release(overloaded bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.free()
+	old := int(a.limit)
+	if overloaded {
+		a.limit = max(a.limit/2, 1)
+	} else {
+		a.limit = min(a.limit+1/a.limit, a.max)
+	}
+	if verbose && int(a.limit) != old {
+		log.Printf("%s concurrency: %d -> %d", a.name, old, int(a.limit))
+	}
+}
+
+// Abandon tells a that a thing ended without telling anything about what it runs against, e.g. because it was canceled.
+func (a *AIMD) Abandon() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.free()
+}
+
+// free frees the slot of a thing and wakes up those waiting for one.
+func (a *AIMD) free() {
+	a.inFlight--
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+// Limit returns how many things are allowed to run at the same time now.
+func (a *AIMD) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.limit)
+}
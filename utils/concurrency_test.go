@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAIMD(t *testing.T) {
+	a := NewAIMD("test", 2, 4)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := a.Acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctxShort, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := a.Acquire(ctxShort); err == nil {
+		t.Fatal("acquired a third slot with a limit of 2")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		a.Acquire(ctx)
+		close(acquired)
+	}()
+	a.Release(false) // 2 + 1/2
+	<-acquired
+	a.Release(false) // 2.5 + 1/2.5
+	a.Release(false) // 2.9 + 1/2.9
+	if l := a.Limit(); l != 3 {
+		t.Errorf("limit = %d after 3 successes, want 3", l)
+	}
+	for i := 0; i < 20; i++ {
+		a.Acquire(ctx)
+		a.Release(false)
+	}
+	if l := a.Limit(); l != 4 {
+		t.Errorf("limit = %d after many successes, want the maximum of 4", l)
+	}
+
+	a.Acquire(ctx)
+	a.Release(true)
+	if l := a.Limit(); l != 2 {
+		t.Errorf("limit = %d after an overload, want 2", l)
+	}
+	for i := 0; i < 3; i++ {
+		a.Acquire(ctx)
+		a.Release(true)
+	}
+	if l := a.Limit(); l != 1 {
+		t.Errorf("limit = %d after overloads, want the minimum of 1", l)
+	}
+	a.Acquire(ctx)
+	a.Abandon()
+	if l := a.Limit(); l != 1 {
+		t.Errorf("limit = %d after an abandoned thing, want it unchanged", l)
+	}
+}
@@ -1,62 +1,205 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
-var apiServer url.URL
+// DefaultAPITimeout is the longest time a single request to the API server may take unless SetAPITimeout is called.
+const DefaultAPITimeout = 30 * time.Second
+
+// apiTimeout is the longest time a single request to the API server may take.
+var apiTimeout = DefaultAPITimeout
 
 // VideoStats unites videos statistics cared in a structure.
 type VideoStats struct {
-	DiggCount int `json:"digg_count"`
-	PlayCount int `json:"play_count"`
+	DiggCount    int `json:"digg_count"`
+	PlayCount    int `json:"play_count"`
+	CommentCount int `json:"comment_count"`
+	ShareCount   int `json:"share_count"`
+	CollectCount int `json:"collect_count"` // favorites
+}
+
+// Music is the sound of a video.
+type Music struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+// Hashtags are the names of the hashtags of a video, without "#". The API server sends them either as strings or as text extras like {"hashtag_name": "ugc"}.
+type Hashtags []string
+
+func (h *Hashtags) UnmarshalJSON(data []byte) error {
+	var extras []json.RawMessage
+	if err := json.Unmarshal(data, &extras); err != nil {
+		return err
+	}
+	*h = nil
+	for _, extra := range extras {
+		var name string
+		if err := json.Unmarshal(extra, &name); err != nil {
+			var textExtra struct {
+				HashtagName string `json:"hashtag_name"`
+			}
+			if err := json.Unmarshal(extra, &textExtra); err != nil {
+				return err
+			}
+			name = textExtra.HashtagName
+		}
+		if name != "" { // text extras of mentions have no hashtag names.
+			*h = append(*h, name)
+		}
+	}
+
+	return nil
 }
 
 // APIResult represents the response from API server.
 type APIResult struct {
 	CreateTime int        `json:"create_time"`
+	Desc       string     `json:"desc"`     // description of the video
+	Duration   int        `json:"duration"` // of the video, in seconds
+	Hashtags   Hashtags   `json:"hashtags"`
+	Music      Music      `json:"music"`
 	Statistics VideoStats `json:"statistics"`
 }
 
-func SetAPIServer(a *url.URL) {
-	apiServer = *a
-	if apiServer.Scheme == "" {
-		apiServer.Scheme = "http"
+// empty tells whether r has nothing in it.
+func (r APIResult) empty() bool {
+	return r.CreateTime == 0 && r.Desc == "" && r.Duration == 0 && len(r.Hashtags) == 0 && r.Music == (Music{}) && r.Statistics == (VideoStats{})
+}
+
+// SetAPITimeout sets how long a single request to the API server may take. 0 means no limit.
+func SetAPITimeout(t time.Duration) {
+	apiTimeout = t
+	if verbose {
+		log.Println("apiTimeout:", apiTimeout)
 	}
 }
 
-// GetVideoStatsFromAPI sends request to apiServer regarding url. It returns createdTime (time the video was posted) and vs (video statistics).
+// DefaultAPIClient returns an APIClient for the first API server set by SetAPIServers which is not ejected. Use DoAPI to balance requests between them.
+func DefaultAPIClient() *APIClient {
+	return &APIClient{Server: apiServers.first(), Timeout: apiTimeout}
+}
+
+// VideoID returns the ID of the video at link, e.g. "7310293679493614853" for "https://www.tiktok.com/@fer.faceyoga/video/7310293679493614853?lang=en", or "" if link is not a video link.
+func VideoID(link string) string {
+	link, _, _ = strings.Cut(link, "?")
+	_, id, _ := strings.Cut(link, "/video/")
+	id, _, _ = strings.Cut(id, "/")
+	return id
+}
+
+// GetVideoStatsFromAPI sends request to the API server regarding url. It returns createdTime (time the video was posted) and vs (video statistics).
 func GetVideoStatsFromAPI(url string) (createdTime int, vs VideoStats, err error) {
-	prefix := apiServer.Scheme + "://" + apiServer.Hostname() + ":" + apiServer.Port() + "/api?url="
-	// fmt.Println(prefix + url)
-	resp, err := http.Get(prefix + url)
+	res, err := GetVideoFromAPI(url)
 	if err != nil {
 		return
 	}
+	return res.CreateTime, res.Statistics, nil
+}
+
+// GetVideoFromAPI sends request to the API server regarding url and returns what it knows about the video.
+func GetVideoFromAPI(url string) (res APIResult, err error) {
+	return DefaultAPIClient().Video(context.Background(), url)
+}
+
+// Errors the API server answers with, which an *APIError wraps.
+var (
+	ErrAPIBusy         = errors.New("api busy")
+	ErrVideoNotFound   = errors.New("video not found")
+	ErrUnsupportedLink = errors.New("unsupported link")
+	ErrAPIServer       = errors.New("api server error")
+	ErrBadJSON         = errors.New("bad json")
+)
+
+// APIError is an error the API server answered a request about Link with. Err is one of ErrAPIBusy, ErrVideoNotFound, ErrUnsupportedLink, ErrAPIServer and ErrBadJSON, so that it can be told with errors.Is.
+type APIError struct {
+	Link       string
+	StatusCode int
+	Message    string // the beginning of the body, if any
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	s := fmt.Sprintf("%s: %s (status %d)", e.Link, e.Err, e.StatusCode)
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	return s
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// maxErrorMessage is the length of the body kept in an APIError.
+const maxErrorMessage = 200
+
+// APIClient sends requests to the API server at Server through the HTTP client set up by SetProxy.
+type APIClient struct {
+	Server  url.URL
+	Timeout time.Duration // of a single request, 0 means no limit
+}
+
+// Video asks the API server about the video at link. Errors other than those of ctx and the connection are *APIErrors.
+func (c *APIClient) Video(ctx context.Context, link string) (APIResult, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	u := c.Server
+	u.Path = "/api"
+	u.RawQuery = url.Values{"url": {link}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return APIResult{}, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return APIResult{}, err
+	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return
+		return APIResult{}, err
+	}
+	apiErr := func(err error) error {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > maxErrorMessage {
+			msg = msg[:maxErrorMessage] + "..."
+		}
+		return &APIError{Link: link, StatusCode: resp.StatusCode, Message: msg, Err: err}
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return APIResult{}, apiErr(ErrAPIBusy)
+	case resp.StatusCode == http.StatusNotFound:
+		return APIResult{}, apiErr(ErrVideoNotFound)
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity:
+		return APIResult{}, apiErr(ErrUnsupportedLink)
+	case resp.StatusCode != http.StatusOK:
+		return APIResult{}, apiErr(ErrAPIServer)
 	}
 
 	var res APIResult
-	if err = json.Unmarshal(data, &res); err != nil {
-		return
+	if err := json.Unmarshal(data, &res); err != nil {
+		return APIResult{}, apiErr(ErrBadJSON)
 	}
-	// if api returns empty result, throw an error
-	var empty APIResult
-	if res == empty {
-		err = ErrAPIBusy
-		return
+	// if api returns empty result, it failed to get the video this time.
+	if res.empty() {
+		return APIResult{}, apiErr(ErrAPIBusy)
 	}
-	createdTime = res.CreateTime
-	// log.Println("👻GetVideoStatsFromAPI: res.Statistics:",res.Statistics)
-	return createdTime, res.Statistics, nil
-}
 
-var ErrAPIBusy = errors.New("api busy")
+	return res, nil
+}
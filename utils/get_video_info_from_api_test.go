@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"path"
+	"reflect"
 	"testing"
 	"time"
 
@@ -10,12 +16,14 @@ import (
 )
 
 func TestGetVideoStatsFromAPI(t *testing.T) {
+	srv := httptest.NewServer(&MockAPI{})
+	defer srv.Close()
 	link := "https://www.tiktok.com/@fer.faceyoga/video/7310293679493614853"
-	a, err := url.Parse("http://127.0.0.1:8000")
+	a, err := url.Parse(srv.URL)
 	if err != nil {
 		t.Error(err)
 	}
-	apiServer = *a
+	SetAPIServer(a)
 	var createdTime int
 	var vs VideoStats
 	if err := backoff.Retry(func() error {
@@ -24,11 +32,64 @@ func TestGetVideoStatsFromAPI(t *testing.T) {
 			fmt.Println(err)
 		}
 		return err
-	}, backoff.NewExponentialBackOff()); err != nil {
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3)); err != nil {
 		t.Error(err)
 	}
 
-	fmt.Println(time.Unix(int64(createdTime), 0))
-	fmt.Println(vs)
-	t.Fail()
+	if want := MockVideo("7310293679493614853"); createdTime != want.CreateTime || vs != want.Statistics {
+		t.Errorf("got %s and %+v, want %+v", time.Unix(int64(createdTime), 0), vs, want)
+	}
+}
+
+func TestAPIClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path.Base(r.URL.Query().Get("url")) {
+		case "1":
+			w.Write([]byte(`{"create_time": 1700000000, "desc": "#ugc @bob", "duration": 15, "hashtags": [{"hashtag_name": "ugc"}, {"hashtag_name": "", "user_id": "1"}], "music": {"title": "original sound", "author": "alice"}, "statistics": {"digg_count": 10, "play_count": 100, "comment_count": 2, "share_count": 1, "collect_count": 4}}`))
+		case "empty":
+			w.Write([]byte(`{}`))
+		case "busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "deleted":
+			http.NotFound(w, r)
+		case "photo":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"detail": "unsupported url"}`))
+		case "html":
+			w.Write([]byte(`<html>proxy error</html>`))
+		case "slow":
+			time.Sleep(300 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	c := &APIClient{Server: *u, Timeout: 100 * time.Millisecond}
+
+	res, err := c.Video(context.Background(), "https://www.tiktok.com/@alice/video/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.CreateTime != 1700000000 || res.Desc != "#ugc @bob" || res.Duration != 15 || !reflect.DeepEqual(res.Hashtags, Hashtags{"ugc"}) || res.Music != (Music{Title: "original sound", Author: "alice"}) || res.Statistics != (VideoStats{DiggCount: 10, PlayCount: 100, CommentCount: 2, ShareCount: 1, CollectCount: 4}) {
+		t.Errorf("got %+v", res)
+	}
+
+	for video, want := range map[string]error{
+		"empty":   ErrAPIBusy,
+		"busy":    ErrAPIBusy,
+		"deleted": ErrVideoNotFound,
+		"photo":   ErrUnsupportedLink,
+		"html":    ErrBadJSON,
+		"broken":  ErrAPIServer,
+	} {
+		_, err := c.Video(context.Background(), "https://www.tiktok.com/@alice/video/"+video)
+		var apiErr *APIError
+		if !errors.Is(err, want) || !errors.As(err, &apiErr) {
+			t.Errorf("%s: got %v, want an APIError of %v", video, err, want)
+		}
+	}
+	if _, err := c.Video(context.Background(), "https://www.tiktok.com/@alice/video/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("slow: got %v, want the deadline exceeded", err)
+	}
 }
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MockVideo makes up what the API server knows about the video with id, the same for the same id every time.
+//
+// Videos are created at the time in the upper 32 bits of their IDs, like the videos on TikTok, or at the start of 2024 if their IDs are not numbers.
+func MockVideo(id string) APIResult {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	sum := int(h.Sum32())
+
+	createTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil && n>>32 != 0 {
+		createTime = int64(n >> 32)
+	}
+	plays := 1000 + sum%100000
+
+	return APIResult{
+		CreateTime: int(createTime),
+		Desc:       "mock video " + id + " #ugc",
+		Duration:   5 + sum%175,
+		Hashtags:   Hashtags{"ugc"},
+		Music:      Music{Title: "original sound - " + id, Author: "mock"},
+		Statistics: VideoStats{
+			PlayCount:    plays,
+			DiggCount:    plays / (10 + sum%20),
+			CommentCount: plays / (100 + sum%50),
+			ShareCount:   plays / (500 + sum%100),
+			CollectCount: plays / (200 + sum%80),
+		},
+	}
+}
+
+// MockAPI serves the same /api?url= contract as the API server, answering with MockVideo, so that the whole pipeline can run without it. It can also act like a struggling API server.
+type MockAPI struct {
+	BusyRate  float64       // share (0 to 1) of requests answered with an empty result, like a busy API server
+	ErrorRate float64       // share (0 to 1) of requests answered with an internal server error
+	Latency   time.Duration // added to every answer
+}
+
+func (m *MockAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api" {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+		}
+		return // answers health checks.
+	}
+	link := r.URL.Query().Get("url")
+	if verbose {
+		log.Println("Mock API:", link)
+	}
+	if m.Latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(m.Latency):
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	id := VideoID(link)
+	switch roll := rand.Float64(); {
+	case id == "":
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"detail": "unsupported url"}`))
+	case roll < m.ErrorRate:
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"detail": "mock error"}`))
+	case roll < m.ErrorRate+m.BusyRate:
+		w.Write([]byte(`{}`))
+	default:
+		json.NewEncoder(w).Encode(MockVideo(id))
+	}
+}
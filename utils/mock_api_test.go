@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMockAPI(t *testing.T) {
+	m := &MockAPI{}
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	c := &APIClient{Server: *u, Timeout: time.Second}
+
+	link := "https://www.tiktok.com/@fer.faceyoga/video/7310293679493614853"
+	res, err := c.Video(context.Background(), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, MockVideo("7310293679493614853")) || time.Unix(int64(res.CreateTime), 0).Year() != 2023 || res.Statistics.PlayCount < 1000 {
+		t.Errorf("got %+v", res)
+	}
+	if _, err := c.Video(context.Background(), "https://www.tiktok.com/@fer.faceyoga"); !errors.Is(err, ErrUnsupportedLink) {
+		t.Errorf("got %v for a profile link, want ErrUnsupportedLink", err)
+	}
+	if err := checkAPIServer(context.Background(), *u); err != nil {
+		t.Errorf("health check failed: %v", err)
+	}
+
+	m.BusyRate = 1
+	if _, err := c.Video(context.Background(), link); !errors.Is(err, ErrAPIBusy) {
+		t.Errorf("got %v with a busy rate of 1, want ErrAPIBusy", err)
+	}
+	m.BusyRate, m.ErrorRate = 0, 1
+	if _, err := c.Video(context.Background(), link); !errors.Is(err, ErrAPIServer) {
+		t.Errorf("got %v with an error rate of 1, want ErrAPIServer", err)
+	}
+	m.ErrorRate, m.Latency = 0, 2*time.Second
+	if _, err := c.Video(context.Background(), link); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v with a latency longer than the timeout, want a timeout", err)
+	}
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pace is the kind of a pause between two actions.
+type Pace int
+
+// Paces of pauses.
+const (
+	ShortPace Pace = iota
+	MediumPace
+	LongPace
+)
+
+// paceRange is the range of the pauses of a pace.
+type paceRange struct {
+	min, max time.Duration
+}
+
+// mean returns the middle of r.
+func (r paceRange) mean() time.Duration {
+	return (r.min + r.max) / 2
+}
+
+// paceRanges are the ranges of the pauses of each pace.
+var paceRanges = map[Pace]paceRange{
+	ShortPace:  {231 * time.Millisecond, 831 * time.Millisecond},
+	MediumPace: {2024 * time.Millisecond, 4021 * time.Millisecond},
+	LongPace:   {7777 * time.Millisecond, 16665 * time.Millisecond},
+}
+
+// Pacer decides how long to pause between two actions.
+type Pacer interface {
+	Pause(p Pace) time.Duration
+}
+
+// FixedPacer always pauses for the middle of the range of a pace.
+type FixedPacer struct{}
+
+func (FixedPacer) Pause(p Pace) time.Duration {
+	return paceRanges[p].mean()
+}
+
+// JitteredPacer pauses for a uniformly random duration in the range of a pace. It is the default.
+type JitteredPacer struct{}
+
+func (JitteredPacer) Pause(p Pace) time.Duration {
+	r := paceRanges[p]
+	return r.min + time.Duration(rand.Int63n(int64(r.max-r.min)))
+}
+
+// PoissonPacer pauses as if actions were a Poisson process: pauses are exponentially distributed, never shorter than the minimum of the range of a pace and as long as its middle on average. Long pauses now and then look less robotic than pauses of similar lengths.
+type PoissonPacer struct{}
+
+func (PoissonPacer) Pause(p Pace) time.Duration {
+	r := paceRanges[p]
+	return r.min + time.Duration(rand.ExpFloat64()*float64(r.mean()-r.min))
+}
+
+// TimeOfDayPacer pauses like Base, but Factor times longer during the quiet hours from QuietFrom (inclusive) to QuietTo (exclusive) in local time, when people browse less. QuietFrom may be greater than QuietTo for hours across midnight.
+type TimeOfDayPacer struct {
+	Base               Pacer
+	QuietFrom, QuietTo int
+	Factor             float64
+	now                func() time.Time // time.Now if nil
+}
+
+func (t TimeOfDayPacer) Pause(p Pace) time.Duration {
+	d := t.Base.Pause(p)
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+	if h := now().Hour(); t.QuietFrom <= t.QuietTo && h >= t.QuietFrom && h < t.QuietTo || t.QuietFrom > t.QuietTo && (h >= t.QuietFrom || h < t.QuietTo) {
+		d = time.Duration(float64(d) * t.Factor)
+	}
+
+	return d
+}
+
+// pacer is the pacer in use.
+var pacer Pacer = JitteredPacer{}
+
+// SetPacing sets the pacer used by ShortInterval, MediumInterval and LongInterval from spec, which is one of:
+//
+//   - "fixed"
+//   - "jittered" (the default)
+//   - "poisson"
+//   - "time-of-day[:<from>-<to>[:<factor>]]", which pauses like "jittered" but factor (3 by default) times longer from hour from to hour to in local time (1-7 by default).
+func SetPacing(spec string) error {
+	name, args, _ := strings.Cut(spec, ":")
+	switch name {
+	case "fixed":
+		pacer = FixedPacer{}
+	case "", "jittered":
+		pacer = JitteredPacer{}
+	case "poisson":
+		pacer = PoissonPacer{}
+	case "time-of-day":
+		p := TimeOfDayPacer{Base: JitteredPacer{}, QuietFrom: 1, QuietTo: 7, Factor: 3}
+		if args != "" {
+			hours, factor, hasFactor := strings.Cut(args, ":")
+			from, to, ok := strings.Cut(hours, "-")
+			var errFrom, errTo error
+			p.QuietFrom, errFrom = strconv.Atoi(from)
+			p.QuietTo, errTo = strconv.Atoi(to)
+			if !ok || errFrom != nil || errTo != nil || p.QuietFrom < 0 || p.QuietFrom > 23 || p.QuietTo < 0 || p.QuietTo > 24 {
+				return fmt.Errorf("invalid quiet hours %q in pacing %q", hours, spec)
+			}
+			if hasFactor {
+				f, err := strconv.ParseFloat(factor, 64)
+				if err != nil || f <= 0 {
+					return fmt.Errorf("invalid factor %q in pacing %q", factor, spec)
+				}
+				p.Factor = f
+			}
+		}
+		pacer = p
+	default:
+		return errors.New("unknown pacing: " + spec)
+	}
+	if name != "" && name != "jittered" {
+		log.Println("Pacing:", spec)
+	}
+
+	return nil
+}
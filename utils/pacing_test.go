@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPacers(t *testing.T) {
+	for _, p := range []Pacer{FixedPacer{}, JitteredPacer{}, PoissonPacer{}} {
+		for pace, r := range paceRanges {
+			for i := 0; i < 100; i++ {
+				if d := p.Pause(pace); d < r.min || p != (PoissonPacer{}) && d >= r.max {
+					t.Fatalf("%T paused %s for pace %d, want in [%s, %s)", p, d, pace, r.min, r.max)
+				}
+			}
+		}
+	}
+	if d := (FixedPacer{}).Pause(MediumPace); d != paceRanges[MediumPace].mean() {
+		t.Errorf("FixedPacer paused %s, want %s", d, paceRanges[MediumPace].mean())
+	}
+}
+
+func TestTimeOfDayPacer(t *testing.T) {
+	p := TimeOfDayPacer{Base: FixedPacer{}, QuietFrom: 22, QuietTo: 6, Factor: 2}
+	base := paceRanges[ShortPace].mean()
+	for hour, want := range map[int]time.Duration{3: 2 * base, 22: 2 * base, 6: base, 12: base} {
+		p.now = func() time.Time { return time.Date(2024, 1, 1, hour, 30, 0, 0, time.Local) }
+		if got := p.Pause(ShortPace); got != want {
+			t.Errorf("paused %s at %d:30, want %s", got, hour, want)
+		}
+	}
+}
+
+func TestSetPacing(t *testing.T) {
+	defer SetPacing("")
+	for _, spec := range []string{"fixed", "jittered", "poisson", "time-of-day", "time-of-day:23-5", "time-of-day:0-8:1.5"} {
+		if err := SetPacing(spec); err != nil {
+			t.Errorf("SetPacing(%q) = %v", spec, err)
+		}
+	}
+	if p, ok := pacer.(TimeOfDayPacer); !ok || p.QuietFrom != 0 || p.QuietTo != 8 || p.Factor != 1.5 {
+		t.Errorf("pacer = %+v", pacer)
+	}
+	for _, spec := range []string{"sloth", "time-of-day:8", "time-of-day:25-3", "time-of-day:1-7:-1"} {
+		if err := SetPacing(spec); err == nil {
+			t.Errorf("SetPacing(%q) = nil, want an error", spec)
+		}
+	}
+}
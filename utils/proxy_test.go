@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestProxyBypassList(t *testing.T) {
+	defer SetProxy("", "")
+	for noProxyList, want := range map[string]string{
+		"":                                      "",
+		"example.com":                           "example.com;*.example.com",
+		".example.com, *.tiktokcdn.com":         "example.com;*.example.com;tiktokcdn.com;*.tiktokcdn.com",
+		"10.0.0.0/8,192.168.1.2,127.0.0.1:8000": "10.0.0.0/8;192.168.1.2;127.0.0.1:8000",
+		"example.com,*":                         "*",
+	} {
+		if err := SetProxy("http://proxy:3128", noProxyList); err != nil {
+			t.Fatal(err)
+		}
+		if got := ProxyBypassList(); got != want {
+			t.Errorf("ProxyBypassList() with %q = %q, want %q", noProxyList, got, want)
+		}
+	}
+}
+
+func TestSetProxy(t *testing.T) {
+	defer SetProxy("", "")
+	for _, p := range []string{"ftp://proxy:21", "http://", "://"} {
+		if err := SetProxy(p, ""); err == nil {
+			t.Errorf("SetProxy(%q) succeeded", p)
+		}
+	}
+
+	var proxied *http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { // a proxy answering every request itself.
+		proxied = r
+		w.Write([]byte(`{"create_time":1,"statistics":{"digg_count":1,"play_count":2}}`))
+	}))
+	defer proxy.Close()
+	u, _ := url.Parse(proxy.URL)
+	u.User = url.UserPassword("user", "pass")
+	if err := SetProxy(u.String(), "bypassed.example"); err != nil {
+		t.Fatal(err)
+	}
+	if Proxy().Host != u.Host {
+		t.Errorf("Proxy() = %v", Proxy())
+	}
+
+	a, _ := url.Parse("http://api.example:8000")
+	SetAPIServer(a)
+	if _, vs, err := GetVideoStatsFromAPI("https://www.tiktok.com/@a/video/1"); err != nil || vs.PlayCount != 2 {
+		t.Fatal(vs, err)
+	}
+	if proxied == nil || proxied.Host != "api.example:8000" {
+		t.Fatalf("request not sent to the proxy: %+v", proxied)
+	}
+	if user, pass, ok := parseProxyAuth(proxied.Header.Get("Proxy-Authorization")); !ok || user != "user" || pass != "pass" {
+		t.Errorf("Proxy-Authorization = %q", proxied.Header.Get("Proxy-Authorization"))
+	}
+
+	proxied = nil
+	a, _ = url.Parse("http://bypassed.example:8000")
+	SetAPIServer(a)
+	GetVideoStatsFromAPI("https://www.tiktok.com/@a/video/1") // fails as the host does not exist, but must not go through the proxy.
+	if proxied != nil {
+		t.Error("request to a host in NO_PROXY sent to the proxy")
+	}
+}
+
+// parseProxyAuth parses a Basic Proxy-Authorization header.
+func parseProxyAuth(h string) (user, pass string, ok bool) {
+	r := &http.Request{Header: http.Header{"Authorization": []string{h}}}
+	return r.BasicAuth()
+}
@@ -1,25 +1,20 @@
 package utils
 
 import (
-	"fmt"
-	"math/rand"
 	"time"
 )
 
+// ShortInterval returns a short pause, e.g. between two clicks, according to the pacing set by SetPacing.
 func ShortInterval() time.Duration {
-	itv := rand.Intn(600) + 231
-	dur, _ := time.ParseDuration(fmt.Sprintf("%dms", itv))
-	return dur
+	return pacer.Pause(ShortPace)
 }
 
+// MediumInterval returns a medium pause, e.g. for a page to settle, according to the pacing set by SetPacing.
 func MediumInterval() time.Duration {
-	itv := rand.Intn(1997) + 2024
-	dur, _ := time.ParseDuration(fmt.Sprintf("%dms", itv))
-	return dur
+	return pacer.Pause(MediumPace)
 }
 
+// LongInterval returns a long pause according to the pacing set by SetPacing.
 func LongInterval() time.Duration {
-	itv := rand.Intn(8888) + 7777
-	dur, _ := time.ParseDuration(fmt.Sprintf("%dms", itv))
-	return dur
+	return pacer.Pause(LongPace)
 }
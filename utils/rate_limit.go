@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// bucket is a token bucket holding up to limit tokens, which are refilled at limit per period.
+type bucket struct {
+	limit  float64
+	period time.Duration
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens gained since the last refill.
+func (b *bucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() / b.period.Seconds() * b.limit
+	if b.tokens > b.limit {
+		b.tokens = b.limit
+	}
+	b.last = now
+}
+
+// wait returns how long it takes to have a token.
+func (b *bucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit * float64(b.period))
+}
+
+// RateLimiter limits how many times something happens per minute and per hour with token buckets. A nil *RateLimiter does not limit anything.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets []*bucket
+	now     func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing perMinute times per minute and perHour times per hour, either of which may be 0 for no limit. nil is returned if both are 0.
+func NewRateLimiter(perMinute, perHour uint) *RateLimiter {
+	l := &RateLimiter{now: time.Now}
+	for _, b := range []bucket{{limit: float64(perMinute), period: time.Minute}, {limit: float64(perHour), period: time.Hour}} {
+		if b.limit != 0 {
+			b := b
+			b.tokens = b.limit
+			b.last = l.now()
+			l.buckets = append(l.buckets, &b)
+		}
+	}
+	if len(l.buckets) == 0 {
+		return nil
+	}
+
+	return l
+}
+
+// Wait blocks until it is allowed to happen once more, or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
+
+// reserve takes a token from every bucket if all of them have one and returns 0, or otherwise returns how long to wait before trying again.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var d time.Duration
+	for _, b := range l.buckets {
+		b.refill(now)
+		d = max(d, b.wait())
+	}
+	if d != 0 {
+		return d
+	}
+	for _, b := range l.buckets {
+		b.tokens--
+	}
+
+	return 0
+}
+
+// Rate limiters of navigations to TikTok and requests to the API server.
+var (
+	navigationLimiter *RateLimiter
+	apiLimiter        *RateLimiter
+)
+
+// SetRateLimits sets how many navigations to TikTok (profiles, searches, tags) and requests to the API server are allowed per minute and per hour. 0 means no limit.
+func SetRateLimits(navigationsPerMinute, navigationsPerHour, apiCallsPerMinute, apiCallsPerHour uint) {
+	navigationLimiter = NewRateLimiter(navigationsPerMinute, navigationsPerHour)
+	apiLimiter = NewRateLimiter(apiCallsPerMinute, apiCallsPerHour)
+	if navigationLimiter != nil || apiLimiter != nil {
+		log.Printf("Rate limits: %d/min and %d/h navigations, %d/min and %d/h API calls (0 for unlimited)", navigationsPerMinute, navigationsPerHour, apiCallsPerMinute, apiCallsPerHour)
+	}
+}
+
+// WaitNavigation blocks until a navigation to TikTok is allowed by the limits set by SetRateLimits, or ctx is done.
+func WaitNavigation(ctx context.Context) error {
+	return navigationLimiter.Wait(ctx)
+}
+
+// WaitAPI blocks until a request to the API server is allowed by the limits set by SetRateLimits, or ctx is done.
+func WaitAPI(ctx context.Context) error {
+	return apiLimiter.Wait(ctx)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if NewRateLimiter(0, 0) != nil {
+		t.Error("NewRateLimiter(0, 0) limits something")
+	}
+	if err := (*RateLimiter)(nil).Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter: %v", err)
+	}
+
+	now := time.Now()
+	l := NewRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+	for _, b := range l.buckets {
+		b.last = now
+	}
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("reservation %d has to wait %s", i+1, d)
+		}
+	}
+	if d := l.reserve(); d != 30*time.Second { // the per-minute limit is hit.
+		t.Fatalf("third reservation has to wait %s, want 30s", d)
+	}
+	now = now.Add(30 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Fatalf("third reservation has to wait %s after 30s", d)
+	}
+	now = now.Add(time.Minute)
+	if d := l.reserve(); d < 15*time.Minute { // the per-hour limit is hit.
+		t.Fatalf("fourth reservation has to wait %s, want the per-hour limit", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Error("Wait() = nil with a canceled context")
+	}
+}
@@ -0,0 +1,9 @@
+package utils
+
+// verbose tells whether to log more details, e.g. how the API server is coped with.
+var verbose bool
+
+// SetVerbose sets verbose to v.
+func SetVerbose(v bool) {
+	verbose = v
+}